@@ -0,0 +1,145 @@
+package application
+
+import (
+	"fmt"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/sant0x00/downloader-music/internal/domain"
+)
+
+type CatalogStatus string
+
+const (
+	CatalogStatusAll        CatalogStatus = "all"
+	CatalogStatusDownloaded CatalogStatus = "downloaded"
+	CatalogStatusMissing    CatalogStatus = "missing"
+)
+
+func ParseCatalogStatus(value string) (CatalogStatus, error) {
+	switch status := CatalogStatus(strings.ToLower(strings.TrimSpace(value))); status {
+	case "":
+		return CatalogStatusAll, nil
+	case CatalogStatusAll, CatalogStatusDownloaded, CatalogStatusMissing:
+		return status, nil
+	default:
+		return "", fmt.Errorf("status inválido: %s (use all, downloaded ou missing)", value)
+	}
+}
+
+type CatalogSort string
+
+const (
+	// CatalogSortRelevance keeps the catalog order, or the search ranking.
+	CatalogSortRelevance CatalogSort = "relevance"
+	CatalogSortTitle     CatalogSort = "title"
+	CatalogSortYear      CatalogSort = "year"
+	CatalogSortSize      CatalogSort = "size"
+)
+
+func ParseCatalogSort(value string) (CatalogSort, error) {
+	switch sortBy := CatalogSort(strings.ToLower(strings.TrimSpace(value))); sortBy {
+	case "":
+		return CatalogSortRelevance, nil
+	case CatalogSortRelevance, CatalogSortTitle, CatalogSortYear, CatalogSortSize:
+		return sortBy, nil
+	default:
+		return "", fmt.Errorf("ordenação inválida: %s (use relevance, title, year ou size)", value)
+	}
+}
+
+// CatalogItem is a remote clip and whether it is already in the library.
+type CatalogItem struct {
+	Clipe   domain.ClipeMusical
+	Baixado bool
+}
+
+// Formato is the audio format of the download, or empty when the clip has
+// no file.
+func (i CatalogItem) Formato() string {
+	if i.Clipe.URLDownload == "" {
+		return ""
+	}
+	return strings.ToUpper(strings.TrimPrefix(path.Ext(i.Clipe.URLDownload), "."))
+}
+
+type CatalogFilter struct {
+	YearFrom   int
+	YearTo     int
+	Status     CatalogStatus
+	Categorias []string
+}
+
+func (f CatalogFilter) Matches(item CatalogItem) bool {
+	if f.YearFrom > 0 && item.Clipe.Ano < f.YearFrom {
+		return false
+	}
+	if f.YearTo > 0 && item.Clipe.Ano > f.YearTo {
+		return false
+	}
+	if len(f.Categorias) > 0 && !slices.Contains(f.Categorias, item.Clipe.Categoria) {
+		return false
+	}
+	switch f.Status {
+	case CatalogStatusDownloaded:
+		return item.Baixado
+	case CatalogStatusMissing:
+		return !item.Baixado
+	}
+	return true
+}
+
+// Catalog lists the remote catalog with the download status of each clip.
+// With a query, only the clips whose title resembles it are kept, ranked as
+// in SearchClipes.
+func (s *DownloadService) Catalog(idiomas []string, query string, filter CatalogFilter) ([]CatalogItem, error) {
+	clipes, _, err := s.scrapClipesList(idiomas)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao obter lista de clipes: %w", err)
+	}
+
+	if query != "" {
+		matches := searchTitles(clipes, query)
+		clipes = clipes[:0]
+		for _, match := range matches {
+			clipes = append(clipes, match.Clipe)
+		}
+	}
+
+	var items []CatalogItem
+	detalhados, errs := s.fetchDetails(clipes)
+	for i, clipe := range clipes {
+		// Clips without a file are still listed, with what the listing has.
+		if errs[i] == nil {
+			clipe = detalhados[i]
+		}
+
+		item := CatalogItem{Clipe: clipe, Baixado: s.repository.Exists(clipe.GetRelativePath())}
+		if filter.Matches(item) {
+			items = append(items, item)
+		}
+	}
+
+	s.logger.Info("Catálogo obtido", "total", len(clipes), "filtrados", len(items))
+	return items, nil
+}
+
+func SortCatalog(items []CatalogItem, sortBy CatalogSort) {
+	sort.SliceStable(items, func(i, j int) bool {
+		a, b := items[i].Clipe, items[j].Clipe
+		switch sortBy {
+		case CatalogSortTitle:
+			return strings.ToLower(a.Titulo) < strings.ToLower(b.Titulo)
+		case CatalogSortYear:
+			if a.Ano != b.Ano {
+				return a.Ano > b.Ano
+			}
+			return a.Faixa < b.Faixa
+		case CatalogSortSize:
+			return a.TamanhoArquivo > b.TamanhoArquivo
+		}
+		return false
+	})
+}
@@ -1,16 +1,29 @@
 package application
 
 import (
+	"errors"
 	"fmt"
+	"path"
+	"strings"
+	"sync"
 
 	"github.com/sant0x00/downloader-music/internal/domain"
 )
 
 type DownloadService struct {
-	scraper    domain.WebScraper
-	downloader domain.DownloadService
-	repository domain.ClipeRepository
-	logger     domain.Logger
+	scraper       domain.WebScraper
+	downloader    domain.DownloadService
+	repository    domain.ClipeRepository
+	logger        domain.Logger
+	filenameStyle domain.FilenameStyle
+	languageMode  LanguageMode
+	publication   string
+	// publicationFolder keeps a publication apart from the others.
+	publicationFolder string
+	yearSource        domain.YearSource
+	categoryRules     []domain.CategoryRule
+	folderLayout      string
+	detailWorkers     int
 }
 
 func NewDownloadService(
@@ -20,146 +33,366 @@ func NewDownloadService(
 	logger domain.Logger,
 ) *DownloadService {
 	return &DownloadService{
-		scraper:    scraper,
-		downloader: downloader,
-		repository: repository,
-		logger:     logger,
+		scraper:       scraper,
+		downloader:    downloader,
+		repository:    repository,
+		logger:        logger,
+		filenameStyle: domain.DefaultFilenameStyle,
+		languageMode:  LanguageModeAll,
+		publication:   domain.DefaultPublication,
+		yearSource:    domain.YearSourceTitle,
+		categoryRules: domain.DefaultCategoryRules,
+		detailWorkers: 1,
 	}
 }
 
-func (s *DownloadService) DownloadAllClipes(baseURL string) error {
+func (s *DownloadService) SetFilenameStyle(style domain.FilenameStyle) {
+	s.filenameStyle = style
+}
+
+func (s *DownloadService) SetLanguageMode(mode LanguageMode) {
+	s.languageMode = mode
+}
+
+// SetPublication tags the scraped clips with their publication and nests
+// them in folder, when given.
+func (s *DownloadService) SetPublication(code, folder string) {
+	s.publication = code
+	s.publicationFolder = folder
+}
+
+// SetYearSource chooses where the year folder of each clip comes from.
+func (s *DownloadService) SetYearSource(source domain.YearSource) {
+	s.yearSource = source
+}
+
+// SetCategoryRules sets the rules clips are classified with, first match
+// wins.
+func (s *DownloadService) SetCategoryRules(rules []domain.CategoryRule) {
+	s.categoryRules = rules
+}
+
+// SetFolderLayout sets the folders below the language and publication ones,
+// e.g. {category}/{year}. Empty keeps one folder per year.
+func (s *DownloadService) SetFolderLayout(layout string) {
+	s.folderLayout = layout
+}
+
+// SetDetailWorkers sets how many clips have their details fetched at once.
+// The scraper rate limit still applies to all of them together.
+func (s *DownloadService) SetDetailWorkers(workers int) {
+	if workers > 0 {
+		s.detailWorkers = workers
+	}
+}
+
+// clipeDetails completes a listing clip with its file and settles its year.
+// The name is computed again, as its extension follows the file format.
+func (s *DownloadService) clipeDetails(clipe domain.ClipeMusical) (domain.ClipeMusical, error) {
+	detalhado, err := s.scraper.ScrapClipeDetails(clipe)
+	if err != nil {
+		return detalhado, err
+	}
+	detalhado.ApplyFilenameStyle(s.filenameStyle)
+	detalhado.ResolveYear(s.yearSource)
+	detalhado.ApplyFolderLayout(s.folderLayout)
+	return detalhado, nil
+}
+
+// RunCatalog is the catalog seen by a download run. Incompleto is set when
+// part of the listing or of the clip details could not be read, so the
+// library must not be mirrored against it.
+type RunCatalog struct {
+	Clipes     []domain.ClipeMusical
+	Incompleto bool
+}
+
+// scrapClipesList lists the catalog of the languages. It also reports
+// whether any listing came back incomplete. Languages other than the
+// default one always get their own folder, so the layout does not depend on
+// the languages of a run.
+func (s *DownloadService) scrapClipesList(idiomas []string) ([]domain.ClipeMusical, bool, error) {
+	var clipes []domain.ClipeMusical
+	incompleto := false
+
+	for _, idioma := range idiomas {
+		lista, err := s.scraper.ScrapClipesList(idioma)
+		if errors.Is(err, domain.ErrIncompleteCatalog) {
+			s.logger.Warn("Lista de clipes incompleta", "idioma", idioma, "erro", err.Error())
+			incompleto = true
+		} else if err != nil {
+			return nil, false, fmt.Errorf("idioma %s: %w", idioma, err)
+		}
+
+		for i := range lista {
+			lista[i].Idioma = idioma
+			lista[i].Publicacao = s.publication
+			lista[i].Pasta = s.publicationFolder
+			if idioma != domain.DefaultLanguage {
+				lista[i].Pasta = path.Join(s.publicationFolder, idioma)
+			}
+			lista[i].ApplyFilenameStyle(s.filenameStyle)
+			lista[i].ResolveYear(s.yearSource)
+			lista[i].Classify(s.categoryRules)
+			lista[i].ApplyFolderLayout(s.folderLayout)
+		}
+
+		clipes = append(clipes, lista...)
+	}
+
+	return clipes, incompleto, nil
+}
+
+// DownloadAllClipes downloads every missing clip and returns the detailed
+// catalog, with the folders the files were saved in, which callers use to
+// mirror the library.
+func (s *DownloadService) DownloadAllClipes(idiomas []string, dryRun bool) (RunCatalog, error) {
 	s.logger.Info("Iniciando processo de download de todos os clipes")
 
-	s.logger.Info("Fazendo scraping da lista de clipes", "url", baseURL)
-	clipes, err := s.scraper.ScrapClipesList(baseURL)
+	s.logger.Info("Fazendo scraping da lista de clipes", "idiomas", strings.Join(idiomas, ","))
+	clipes, incompleto, err := s.scrapClipesList(idiomas)
 	if err != nil {
 		s.logger.Error("Erro ao fazer scraping da lista", err)
-		return fmt.Errorf("erro ao obter lista de clipes: %w", err)
+		return RunCatalog{}, fmt.Errorf("erro ao obter lista de clipes: %w", err)
 	}
 
 	if len(clipes) == 0 {
 		s.logger.Warn("Nenhum clipe encontrado na página")
-		return fmt.Errorf("nenhum clipe encontrado")
+		return RunCatalog{}, fmt.Errorf("nenhum clipe encontrado")
 	}
 
 	s.logger.Info("Lista de clipes obtida", "total", len(clipes))
 
-	s.logger.Info("Obtendo detalhes dos clipes")
-	var clipesValidos []domain.ClipeMusical
-
-	for i, clipe := range clipes {
-		s.logger.Debug("Processando clipe", "index", i+1, "total", len(clipes), "titulo", clipe.Titulo)
-
-		clipeDetalhado, err := s.scraper.ScrapClipeDetails(clipe)
-		if err != nil {
-			s.logger.Error("Erro ao obter detalhes do clipe", err, "titulo", clipe.Titulo)
-			continue
-		}
-
-		if !clipeDetalhado.IsValid() {
-			s.logger.Warn("Clipe inválido, pulando", "titulo", clipe.Titulo, "url_download", clipeDetalhado.URLDownload)
-			continue
-		}
-
-		clipesValidos = append(clipesValidos, clipeDetalhado)
-	}
+	detalhados, falhas := s.detailedClipes(clipes)
+	clipesValidos := s.selectByLanguageMode(detalhados, idiomas)
+	catalog := RunCatalog{Clipes: clipesValidos, Incompleto: incompleto || falhas > 0}
 
 	if len(clipesValidos) == 0 {
 		s.logger.Error("Nenhum clipe válido encontrado", fmt.Errorf("sem clipes para download"))
-		return fmt.Errorf("nenhum clipe válido encontrado")
+		return RunCatalog{}, fmt.Errorf("nenhum clipe válido encontrado")
 	}
 
 	s.logger.Info("Clipes válidos encontrados", "total", len(clipesValidos))
 
 	var clipesParaDownload []domain.ClipeMusical
 	for _, clipe := range clipesValidos {
-		filename := clipe.GetSanitizedFilename()
-		if !s.repository.Exists(filename) {
+		relPath := clipe.GetRelativePath()
+		if !s.repository.Exists(relPath) {
 			clipesParaDownload = append(clipesParaDownload, clipe)
 		} else {
-			s.logger.Info("Clipe já existe, pulando", "titulo", clipe.Titulo, "arquivo", filename)
+			s.logger.Info("Clipe já existe, pulando", "titulo", clipe.Titulo, "arquivo", relPath)
 		}
 	}
 
 	if len(clipesParaDownload) == 0 {
 		s.logger.Info("Todos os clipes já foram baixados")
-		return nil
+		return catalog, nil
 	}
 
 	s.logger.Info("Clipes para download", "novos", len(clipesParaDownload), "existentes", len(clipesValidos)-len(clipesParaDownload))
 
+	if dryRun {
+		for _, clipe := range clipesParaDownload {
+			s.logger.Info("Simulação: clipe seria baixado", "titulo", clipe.Titulo, "arquivo", clipe.GetSanitizedFilename())
+		}
+		return catalog, nil
+	}
+
 	outputDir := s.repository.GetOutputDirectory()
 	err = s.downloader.DownloadBatch(clipesParaDownload, outputDir)
 	if err != nil {
 		s.logger.Error("Erro durante download em lote", err)
-		return fmt.Errorf("erro durante download: %w", err)
+		return RunCatalog{}, fmt.Errorf("erro durante download: %w", err)
 	}
 
 	s.logger.Info("Processo de download concluído com sucesso", "total_baixados", len(clipesParaDownload))
-	return nil
+	return catalog, nil
+}
+
+// fetchDetails runs clipeDetails over the clips with a bounded pool of
+// workers. Results and errors keep the order of the clips.
+func (s *DownloadService) fetchDetails(clipes []domain.ClipeMusical) ([]domain.ClipeMusical, []error) {
+	detalhados := make([]domain.ClipeMusical, len(clipes))
+	errs := make([]error, len(clipes))
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < min(s.detailWorkers, len(clipes)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				s.logger.Debug("Processando clipe", "index", i+1, "total", len(clipes), "titulo", clipes[i].Titulo)
+				detalhados[i], errs[i] = s.clipeDetails(clipes[i])
+			}
+		}()
+	}
+
+	for i := range clipes {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	return detalhados, errs
 }
 
-func (s *DownloadService) CheckForNewClipes(baseURL string) ([]domain.ClipeMusical, error) {
+// detailedClipes returns the valid clips with their details, and how many
+// could not have their details read.
+func (s *DownloadService) detailedClipes(clipes []domain.ClipeMusical) ([]domain.ClipeMusical, int) {
+	s.logger.Info("Obtendo detalhes dos clipes", "workers", s.detailWorkers)
+	var clipesValidos []domain.ClipeMusical
+	falhas := 0
+
+	detalhados, errs := s.fetchDetails(clipes)
+	for i, clipe := range clipes {
+		clipeDetalhado, err := detalhados[i], errs[i]
+		if err != nil {
+			s.logger.Error("Erro ao obter detalhes do clipe", err, "titulo", clipe.Titulo)
+			falhas++
+			continue
+		}
+
+		if !clipeDetalhado.IsValid() {
+			s.logger.Warn("Clipe inválido, pulando", "titulo", clipe.Titulo, "url_download", clipeDetalhado.URLDownload)
+			continue
+		}
+
+		clipesValidos = append(clipesValidos, clipeDetalhado)
+	}
+
+	s.reportUnmatched(len(clipes) - len(clipesValidos))
+	return clipesValidos, falhas
+}
+
+// reportUnmatched logs the listing items and API files that could not be
+// paired with each other.
+func (s *DownloadService) reportUnmatched(listingItems int) {
+	if listingItems > 0 {
+		s.logger.Warn("Clipes da listagem sem arquivo correspondente na API", "total", listingItems)
+	}
+
+	reporter, ok := s.scraper.(domain.MatchReporter)
+	if !ok {
+		return
+	}
+	unmatched := reporter.UnmatchedRemoteFiles()
+	for _, file := range unmatched {
+		s.logger.Warn("Arquivo da API sem clipe correspondente na listagem", "titulo", file.Titulo, "idioma", file.Idioma, "url", file.URLDownload)
+	}
+	if len(unmatched) > 0 {
+		s.logger.Warn("Arquivos da API sem clipe correspondente", "total", len(unmatched))
+	}
+}
+
+func (s *DownloadService) CheckForNewClipes(idiomas []string) ([]domain.ClipeMusical, error) {
 	s.logger.Info("Verificando novos clipes disponíveis")
 
-	clipes, err := s.scraper.ScrapClipesList(baseURL)
+	clipes, _, err := s.scrapClipesList(idiomas)
 	if err != nil {
 		return nil, fmt.Errorf("erro ao obter lista de clipes: %w", err)
 	}
 
+	// Matching songs across languages needs the details of every clip.
+	if s.languageMode != LanguageModeAll && len(idiomas) > 1 {
+		detalhados, _ := s.detailedClipes(clipes)
+		clipes = s.selectByLanguageMode(detalhados, idiomas)
+	}
+
 	var novosClipes []domain.ClipeMusical
+	var semDetalhes []int
 	for _, clipe := range clipes {
-		filename := clipe.GetSanitizedFilename()
-		if !s.repository.Exists(filename) {
-			novosClipes = append(novosClipes, clipe)
+		if s.repository.Exists(clipe.GetRelativePath()) {
+			continue
+		}
+		if clipe.URLDownload == "" {
+			semDetalhes = append(semDetalhes, len(novosClipes))
+		}
+		novosClipes = append(novosClipes, clipe)
+	}
+
+	// Details carry the duration shown to the user; the listing is enough
+	// when they cannot be fetched.
+	pendentes := make([]domain.ClipeMusical, len(semDetalhes))
+	for i, index := range semDetalhes {
+		pendentes[i] = novosClipes[index]
+	}
+	detalhados, errs := s.fetchDetails(pendentes)
+	baixados := make(map[int]bool)
+	for i, index := range semDetalhes {
+		if errs[i] == nil {
+			novosClipes[index] = detalhados[i]
+			// In another format the clip has another name, maybe downloaded.
+			baixados[index] = s.repository.Exists(detalhados[i].GetRelativePath())
+		}
+	}
+	var faltando []domain.ClipeMusical
+	for i, clipe := range novosClipes {
+		if !baixados[i] {
+			faltando = append(faltando, clipe)
 		}
 	}
+	novosClipes = faltando
 
 	s.logger.Info("Verificação concluída", "total_clipes", len(clipes), "novos_clipes", len(novosClipes))
 	return novosClipes, nil
 }
 
-func (s *DownloadService) DownloadSpecificClipe(baseURL, titulo string) error {
+// SearchClipes ranks the catalog clips whose title resembles titulo, see
+// searchTitles.
+func (s *DownloadService) SearchClipes(idiomas []string, titulo string) ([]TitleMatch, error) {
 	s.logger.Info("Procurando clipe específico", "titulo", titulo)
 
-	clipes, err := s.scraper.ScrapClipesList(baseURL)
+	clipes, _, err := s.scrapClipesList(idiomas)
 	if err != nil {
-		return fmt.Errorf("erro ao obter lista de clipes: %w", err)
+		return nil, fmt.Errorf("erro ao obter lista de clipes: %w", err)
 	}
 
-	var clipeEncontrado *domain.ClipeMusical
-	for _, clipe := range clipes {
-		if clipe.Titulo == titulo {
-			clipeEncontrado = &clipe
-			break
-		}
+	matches := searchTitles(clipes, titulo)
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("clipe não encontrado: %s", titulo)
 	}
 
-	if clipeEncontrado == nil {
-		return fmt.Errorf("clipe não encontrado: %s", titulo)
-	}
+	s.logger.Info("Clipes encontrados", "titulo", titulo, "total", len(matches))
+	return matches, nil
+}
 
-	clipeDetalhado, err := s.scraper.ScrapClipeDetails(*clipeEncontrado)
-	if err != nil {
-		return fmt.Errorf("erro ao obter detalhes do clipe: %w", err)
-	}
+// DownloadSelectedClipes downloads the clips picked from a search, skipping
+// the ones already in the library.
+func (s *DownloadService) DownloadSelectedClipes(clipes []domain.ClipeMusical) error {
+	var clipesParaDownload []domain.ClipeMusical
+	for _, clipe := range clipes {
+		clipeDetalhado, err := s.clipeDetails(clipe)
+		if err != nil {
+			return fmt.Errorf("erro ao obter detalhes do clipe: %w", err)
+		}
 
-	if !clipeDetalhado.IsValid() {
-		return fmt.Errorf("clipe inválido: %s", titulo)
-	}
+		if !clipeDetalhado.IsValid() {
+			return fmt.Errorf("clipe inválido: %s", clipe.Titulo)
+		}
 
-	filename := clipeDetalhado.GetSanitizedFilename()
-	if s.repository.Exists(filename) {
-		s.logger.Info("Clipe já existe", "titulo", titulo, "arquivo", filename)
-		return nil
+		relPath := clipeDetalhado.GetRelativePath()
+		if s.repository.Exists(relPath) {
+			s.logger.Info("Clipe já existe", "titulo", clipe.Titulo, "arquivo", relPath)
+			continue
+		}
+		clipesParaDownload = append(clipesParaDownload, clipeDetalhado)
 	}
 
 	outputDir := s.repository.GetOutputDirectory()
-	err = s.downloader.Download(clipeDetalhado, outputDir)
-	if err != nil {
-		return fmt.Errorf("erro no download: %w", err)
+	switch len(clipesParaDownload) {
+	case 0:
+		return nil
+	case 1:
+		if err := s.downloader.Download(clipesParaDownload[0], outputDir); err != nil {
+			return fmt.Errorf("erro no download: %w", err)
+		}
+	default:
+		if err := s.downloader.DownloadBatch(clipesParaDownload, outputDir); err != nil {
+			return fmt.Errorf("erro no download: %w", err)
+		}
 	}
 
-	s.logger.Info("Download do clipe específico concluído", "titulo", titulo)
+	s.logger.Info("Download dos clipes escolhidos concluído", "total", len(clipesParaDownload))
 	return nil
 }
@@ -0,0 +1,140 @@
+package application
+
+import (
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/sant0x00/downloader-music/internal/domain"
+	"github.com/sant0x00/downloader-music/pkg/logger"
+)
+
+// fakeScraper serves a fixed catalog, as the API mode does: the listing
+// already carries the files and their publication dates.
+type fakeScraper struct {
+	clipes []domain.ClipeMusical
+}
+
+func (f *fakeScraper) ScrapClipesList(idioma string) ([]domain.ClipeMusical, error) {
+	return append([]domain.ClipeMusical(nil), f.clipes...), nil
+}
+
+func (f *fakeScraper) ScrapClipeDetails(clipe domain.ClipeMusical) (domain.ClipeMusical, error) {
+	return clipe, nil
+}
+
+// fakeLibrary is a repository and downloader keeping the saved paths.
+type fakeLibrary struct {
+	paths map[string]bool
+}
+
+func (f *fakeLibrary) FindAll() ([]domain.ClipeMusical, error)            { return nil, nil }
+func (f *fakeLibrary) Save(clipe domain.ClipeMusical) error               { return nil }
+func (f *fakeLibrary) Exists(relPath string) bool                         { return f.paths[relPath] }
+func (f *fakeLibrary) GetOutputDirectory() string                         { return "" }
+func (f *fakeLibrary) CreateDirectoryStructure(domain.ClipeMusical) error { return nil }
+func (f *fakeLibrary) OpenWriter(domain.ClipeMusical) (domain.ClipeWriter, error) {
+	return nil, nil
+}
+
+func (f *fakeLibrary) Download(clipe domain.ClipeMusical, destPath string) error {
+	f.paths[clipe.GetRelativePath()] = true
+	return nil
+}
+
+func (f *fakeLibrary) DownloadBatch(clipes []domain.ClipeMusical, destPath string) error {
+	for _, clipe := range clipes {
+		f.Download(clipe, destPath)
+	}
+	return nil
+}
+
+func (f *fakeLibrary) ReplaceBatch(clipes []domain.ClipeMusical, destPath string) error {
+	return f.DownloadBatch(clipes, destPath)
+}
+
+func (f *fakeLibrary) SetProgressCallback(func(current, total int64, filename string)) {}
+
+func TestCheckAndDownloadAgreeOnPaths(t *testing.T) {
+	published := time.Date(2023, 5, 10, 0, 0, 0, 0, time.UTC)
+	catalog := []domain.ClipeMusical{
+		{Titulo: "Alegria (2024)", URL: "https://jw.example/alegria", URLDownload: "https://cdn.example/alegria.mp3", DataPublicacao: published},
+		{Titulo: "Coragem", URL: "https://jw.example/coragem", URLDownload: "https://cdn.example/coragem.mp3", DataPublicacao: published},
+		{Titulo: "Congresso de 2022 - Paz", URL: "https://jw.example/paz", URLDownload: "https://cdn.example/paz.m4a"},
+	}
+
+	tests := []struct {
+		source domain.YearSource
+		layout string
+		want   []string
+	}{
+		{domain.YearSourceTitle, "", []string{"2022/Congresso_de_2022_Paz.m4a", "2024/Alegria_2024.mp3", "outros/Coragem.mp3"}},
+		{domain.YearSourcePublication, "", []string{"2022/Congresso_de_2022_Paz.m4a", "2023/Alegria_2024.mp3", "2023/Coragem.mp3"}},
+		{domain.YearSourceTitle, "{category}/{year}", nil},
+	}
+
+	log, err := logger.NewSimpleLogger("error", "")
+	if err != nil {
+		t.Fatalf("logger: %v", err)
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.source)+" "+tt.layout, func(t *testing.T) {
+			library := &fakeLibrary{paths: make(map[string]bool)}
+			service := NewDownloadService(&fakeScraper{clipes: catalog}, library, library, log)
+			service.SetYearSource(tt.source)
+			service.SetFolderLayout(tt.layout)
+			service.SetFilenameStyle(domain.FilenameStyleASCIISnake)
+
+			novos, err := service.CheckForNewClipes([]string{"T"})
+			if err != nil || len(novos) != len(catalog) {
+				t.Fatalf("check before download = %d clips, %v", len(novos), err)
+			}
+			var expected []string
+			for _, clipe := range novos {
+				expected = append(expected, clipe.GetRelativePath())
+			}
+
+			if _, err := service.DownloadAllClipes([]string{"T"}, false); err != nil {
+				t.Fatalf("DownloadAllClipes: %v", err)
+			}
+			var saved []string
+			for relPath := range library.paths {
+				saved = append(saved, relPath)
+			}
+			sort.Strings(expected)
+			sort.Strings(saved)
+			if len(saved) != len(expected) {
+				t.Fatalf("saved %v, check expected %v", saved, expected)
+			}
+			for i := range saved {
+				if saved[i] != expected[i] {
+					t.Fatalf("saved %v, check expected %v", saved, expected)
+				}
+			}
+			if tt.want != nil {
+				for i := range tt.want {
+					if saved[i] != tt.want[i] {
+						t.Errorf("saved %v, want %v", saved, tt.want)
+						break
+					}
+				}
+			}
+
+			novos, err = service.CheckForNewClipes([]string{"T"})
+			if err != nil || len(novos) != 0 {
+				t.Errorf("check after download = %d clips, %v", len(novos), err)
+			}
+
+			items, err := service.Catalog([]string{"T"}, "", CatalogFilter{})
+			if err != nil {
+				t.Fatalf("Catalog: %v", err)
+			}
+			for _, item := range items {
+				if !item.Baixado {
+					t.Errorf("catalog lists %s as missing", item.Clipe.GetRelativePath())
+				}
+			}
+		})
+	}
+}
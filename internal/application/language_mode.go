@@ -0,0 +1,80 @@
+package application
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sant0x00/downloader-music/internal/domain"
+)
+
+type LanguageMode string
+
+const (
+	// LanguageModeAll downloads the whole catalog of every language.
+	LanguageModeAll LanguageMode = "all"
+	// LanguageModeFallback downloads each song once, in the first configured
+	// language it is published in.
+	LanguageModeFallback LanguageMode = "fallback"
+	// LanguageModePair downloads each song in the first two languages.
+	LanguageModePair LanguageMode = "pair"
+)
+
+func ParseLanguageMode(value string) (LanguageMode, error) {
+	switch mode := LanguageMode(strings.ToLower(strings.TrimSpace(value))); mode {
+	case "":
+		return LanguageModeAll, nil
+	case LanguageModeAll, LanguageModeFallback, LanguageModePair:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("modo de idiomas inválido: %s (use all, fallback ou pair)", value)
+	}
+}
+
+// selectByLanguageMode picks the clips to keep among detailed clips of all
+// languages. Songs are matched across languages by their Chave.
+func (s *DownloadService) selectByLanguageMode(clipes []domain.ClipeMusical, idiomas []string) []domain.ClipeMusical {
+	if s.languageMode == LanguageModeAll || len(idiomas) < 2 {
+		return clipes
+	}
+
+	rank := make(map[string]int, len(idiomas))
+	for i, idioma := range idiomas {
+		rank[idioma] = i
+	}
+
+	if s.languageMode == LanguageModePair {
+		var selecionados []domain.ClipeMusical
+		for _, clipe := range clipes {
+			if rank[clipe.GetIdioma()] < 2 {
+				selecionados = append(selecionados, clipe)
+			}
+		}
+		return selecionados
+	}
+
+	best := make(map[string]string)
+	for _, clipe := range clipes {
+		if clipe.Chave == "" {
+			continue
+		}
+		current, exists := best[clipe.Chave]
+		if !exists || rank[clipe.GetIdioma()] < rank[current] {
+			best[clipe.Chave] = clipe.GetIdioma()
+		}
+	}
+
+	var selecionados []domain.ClipeMusical
+	for _, clipe := range clipes {
+		switch {
+		case clipe.Chave == "" && clipe.GetIdioma() == idiomas[0]:
+		case clipe.Chave != "" && best[clipe.Chave] == clipe.GetIdioma():
+			if clipe.GetIdioma() != idiomas[0] {
+				s.logger.Info("Clipe indisponível no idioma principal, usando alternativa", "titulo", clipe.Titulo, "idioma", clipe.GetIdioma())
+			}
+		default:
+			continue
+		}
+		selecionados = append(selecionados, clipe)
+	}
+	return selecionados
+}
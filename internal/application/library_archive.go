@@ -0,0 +1,447 @@
+package application
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/sant0x00/downloader-music/internal/domain"
+)
+
+const archiveManifestName = "manifest.json"
+
+// archiveSidecarExtensions are the files carried along with a clip.
+var archiveSidecarExtensions = map[string]bool{".lrc": true, ".txt": true, ".jpg": true}
+
+type ArchiveFormat string
+
+const (
+	ArchiveFormatZip   ArchiveFormat = "zip"
+	ArchiveFormatTarGz ArchiveFormat = "tar.gz"
+)
+
+func ParseArchiveFormat(value string) (ArchiveFormat, error) {
+	switch format := ArchiveFormat(strings.ToLower(strings.TrimSpace(value))); format {
+	case ArchiveFormatZip, ArchiveFormatTarGz:
+		return format, nil
+	case "tgz":
+		return ArchiveFormatTarGz, nil
+	default:
+		return "", fmt.Errorf("formato de arquivo inválido: %s (use zip ou tar.gz)", value)
+	}
+}
+
+// DetectArchiveFormat infers the format from an archive file name.
+func DetectArchiveFormat(filename string) (ArchiveFormat, error) {
+	lower := strings.ToLower(filename)
+	switch {
+	case strings.HasSuffix(lower, ".zip"):
+		return ArchiveFormatZip, nil
+	case strings.HasSuffix(lower, ".tar.gz"), strings.HasSuffix(lower, ".tgz"):
+		return ArchiveFormatTarGz, nil
+	default:
+		return "", fmt.Errorf("não foi possível identificar o formato de %s", filename)
+	}
+}
+
+type ExportFilter struct {
+	YearFrom int
+	YearTo   int
+	Since    time.Time
+	Until    time.Time
+	Idiomas  []string
+	// Categorias keeps only clips of these series.
+	Categorias []string
+}
+
+func (f ExportFilter) Matches(entry domain.ManifestEntry) bool {
+	if f.YearFrom > 0 && entry.Ano < f.YearFrom {
+		return false
+	}
+	if f.YearTo > 0 && entry.Ano > f.YearTo {
+		return false
+	}
+	if !f.Since.IsZero() && entry.DownloadedAt.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && entry.DownloadedAt.After(f.Until) {
+		return false
+	}
+	if len(f.Categorias) > 0 && !slices.Contains(f.Categorias, entry.Categoria) {
+		return false
+	}
+	if len(f.Idiomas) > 0 && !slices.Contains(f.Idiomas, entry.GetIdioma()) {
+		return false
+	}
+	return true
+}
+
+type ImportResult struct {
+	Imported []string
+	Skipped  []string
+	Failed   map[string]string
+}
+
+// Export writes the selected clips, their sidecars and a manifest with their
+// entries into a single archive.
+func (s *LibraryService) Export(w io.Writer, format ArchiveFormat, filter ExportFilter) (int, error) {
+	manifest, err := s.storage.ReadManifest()
+	if err != nil {
+		return 0, err
+	}
+
+	selected := domain.NewManifest()
+	for _, entry := range manifest.SortedEntries() {
+		if filter.Matches(entry) && s.storage.FileExists(entry.Path) {
+			selected.Put(entry)
+		}
+	}
+
+	if len(selected.Entries) == 0 {
+		return 0, fmt.Errorf("nenhum clipe corresponde aos filtros")
+	}
+
+	s.logger.Info("Exportando biblioteca", "formato", format, "clipes", len(selected.Entries))
+
+	archive, err := newArchiveWriter(w, format)
+	if err != nil {
+		return 0, err
+	}
+
+	manifestData, err := json.MarshalIndent(selected, "", "  ")
+	if err != nil {
+		return 0, fmt.Errorf("erro ao codificar manifesto: %w", err)
+	}
+	// The manifest goes first so streaming readers can use it right away.
+	if err := archive.add(archiveManifestName, int64(len(manifestData)), time.Now(), bytes.NewReader(manifestData)); err != nil {
+		return 0, err
+	}
+
+	for _, entry := range selected.SortedEntries() {
+		paths := []string{entry.Path}
+		sidecars, err := s.storage.FindSidecars(entry.Path)
+		if err != nil {
+			s.logger.Warn("Não foi possível listar arquivos auxiliares", "arquivo", entry.Path, "erro", err.Error())
+		}
+		for _, sidecar := range sidecars {
+			if isArchiveSidecar(sidecar) {
+				paths = append(paths, sidecar)
+			}
+		}
+
+		for _, filePath := range paths {
+			if err := s.addToArchive(archive, filePath); err != nil {
+				return 0, err
+			}
+		}
+	}
+
+	if err := archive.close(); err != nil {
+		return 0, fmt.Errorf("erro ao finalizar arquivo: %w", err)
+	}
+
+	s.logger.Info("Exportação concluída", "clipes", len(selected.Entries))
+	return len(selected.Entries), nil
+}
+
+// Import merges an archive created by Export into the library, verifying
+// every clip against its checksum and skipping what is already present.
+func (s *LibraryService) Import(r io.ReaderAt, size int64, format ArchiveFormat) (*ImportResult, error) {
+	reader, err := newArchiveReader(r, size, format)
+	if err != nil {
+		return nil, err
+	}
+
+	local, err := s.storage.ReadManifest()
+	if err != nil {
+		return nil, err
+	}
+
+	localHashes := make(map[string]bool)
+	for _, entry := range local.Entries {
+		if entry.SHA256 != "" {
+			localHashes[entry.SHA256] = true
+		}
+	}
+
+	result := &ImportResult{Failed: make(map[string]string)}
+	var incoming *domain.Manifest
+	var imported []domain.ManifestEntry
+	// Stems of the clips at their archive path after the import. Export
+	// writes the sidecars right after their clip, so they are known in time.
+	clipStems := make(map[string]bool)
+
+	err = reader.each(func(name string, content io.Reader) error {
+		name = path.Clean(strings.TrimPrefix(name, "/"))
+		if strings.HasPrefix(name, "../") || name == ".." {
+			result.Failed[name] = "caminho inválido"
+			return nil
+		}
+
+		if name == archiveManifestName {
+			incoming = domain.NewManifest()
+			if err := json.NewDecoder(content).Decode(incoming); err != nil {
+				return fmt.Errorf("erro ao decodificar manifesto do arquivo: %w", err)
+			}
+			return nil
+		}
+
+		if incoming == nil {
+			return fmt.Errorf("manifesto ausente no início do arquivo")
+		}
+
+		entry, isClip := incoming.Get(name)
+		if !isClip {
+			// Sidecar: only written when missing.
+			if !isArchiveSidecar(name) || !clipStems[archiveStem(name)] {
+				result.Failed[name] = "arquivo não pertence a nenhum clipe importado"
+				return nil
+			}
+			if s.storage.FileExists(name) {
+				return nil
+			}
+			if err := s.writeImported(name, content, ""); err != nil {
+				s.logger.Error("Erro ao importar arquivo auxiliar", err, "arquivo", name)
+				result.Failed[name] = err.Error()
+			}
+			return nil
+		}
+
+		present := s.storage.FileExists(name)
+		if present {
+			clipStems[archiveStem(name)] = true
+		}
+		if localHashes[entry.SHA256] || present || s.storage.Exists(name) {
+			result.Skipped = append(result.Skipped, name)
+			return nil
+		}
+
+		if err := s.writeImported(name, content, entry.SHA256); err != nil {
+			s.logger.Error("Erro ao importar clipe", err, "arquivo", name)
+			result.Failed[name] = err.Error()
+			return nil
+		}
+
+		localHashes[entry.SHA256] = true
+		clipStems[archiveStem(name)] = true
+		imported = append(imported, entry)
+		result.Imported = append(result.Imported, name)
+		return nil
+	})
+	if err != nil {
+		return result, err
+	}
+
+	if len(imported) > 0 {
+		err = s.storage.UpdateManifest(func(m *domain.Manifest) {
+			for _, entry := range imported {
+				m.Put(entry)
+			}
+		})
+		if err != nil {
+			return result, err
+		}
+	}
+
+	s.logger.Info("Importação concluída", "importados", len(result.Imported), "ignorados", len(result.Skipped), "falhas", len(result.Failed))
+	return result, nil
+}
+
+// isArchiveSidecar accepts lyrics and images, never hidden files such as the
+// action log.
+func isArchiveSidecar(name string) bool {
+	base := path.Base(name)
+	return !strings.HasPrefix(base, ".") && archiveSidecarExtensions[strings.ToLower(path.Ext(base))]
+}
+
+func archiveStem(name string) string {
+	return strings.TrimSuffix(name, path.Ext(name))
+}
+
+func (s *LibraryService) addToArchive(archive archiveWriter, filePath string) error {
+	reader, err := s.storage.OpenFile(filePath)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	// tar needs the size up front, so ask the storage instead of buffering.
+	size, err := s.storage.FileSize(filePath)
+	if err != nil {
+		return err
+	}
+
+	if err := archive.add(filePath, size, time.Now(), reader); err != nil {
+		return fmt.Errorf("erro ao ler %s: %w", filePath, err)
+	}
+	return nil
+}
+
+func (s *LibraryService) writeImported(name string, content io.Reader, expectedSHA256 string) error {
+	out, err := s.storage.CreateFile(name)
+	if err != nil {
+		return err
+	}
+
+	hash := domain.NewPayloadHash()
+	if _, err := io.Copy(io.MultiWriter(out, hash), content); err != nil {
+		out.Abort()
+		return fmt.Errorf("erro ao extrair %s: %w", name, err)
+	}
+
+	if expectedSHA256 != "" && hash.Sum() != expectedSHA256 {
+		out.Abort()
+		return fmt.Errorf("checksum SHA-256 não confere")
+	}
+
+	return out.Commit()
+}
+
+type archiveWriter interface {
+	add(name string, size int64, modTime time.Time, content io.Reader) error
+	close() error
+}
+
+type archiveReader interface {
+	each(fn func(name string, content io.Reader) error) error
+}
+
+func newArchiveWriter(w io.Writer, format ArchiveFormat) (archiveWriter, error) {
+	switch format {
+	case ArchiveFormatZip:
+		return &zipArchiveWriter{zip: zip.NewWriter(w)}, nil
+	case ArchiveFormatTarGz:
+		gz := gzip.NewWriter(w)
+		return &tarArchiveWriter{gzip: gz, tar: tar.NewWriter(gz)}, nil
+	default:
+		return nil, fmt.Errorf("formato de arquivo inválido: %s", format)
+	}
+}
+
+func newArchiveReader(r io.ReaderAt, size int64, format ArchiveFormat) (archiveReader, error) {
+	switch format {
+	case ArchiveFormatZip:
+		zr, err := zip.NewReader(r, size)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao abrir zip: %w", err)
+		}
+		return &zipArchiveReader{zip: zr}, nil
+	case ArchiveFormatTarGz:
+		return &tarArchiveReader{source: io.NewSectionReader(r, 0, size)}, nil
+	default:
+		return nil, fmt.Errorf("formato de arquivo inválido: %s", format)
+	}
+}
+
+type zipArchiveWriter struct {
+	zip *zip.Writer
+}
+
+func (a *zipArchiveWriter) add(name string, size int64, modTime time.Time, content io.Reader) error {
+	header := &zip.FileHeader{Name: name, Method: zip.Deflate, Modified: modTime}
+	// Audio is already compressed; storing it is much faster.
+	if !strings.HasSuffix(name, ".json") && !strings.HasSuffix(name, ".txt") && !strings.HasSuffix(name, ".lrc") {
+		header.Method = zip.Store
+	}
+
+	w, err := a.zip.CreateHeader(header)
+	if err != nil {
+		return fmt.Errorf("erro ao adicionar %s: %w", name, err)
+	}
+	_, err = io.Copy(w, content)
+	return err
+}
+
+func (a *zipArchiveWriter) close() error {
+	return a.zip.Close()
+}
+
+type tarArchiveWriter struct {
+	gzip *gzip.Writer
+	tar  *tar.Writer
+}
+
+func (a *tarArchiveWriter) add(name string, size int64, modTime time.Time, content io.Reader) error {
+	header := &tar.Header{Name: name, Size: size, Mode: 0644, ModTime: modTime, Typeflag: tar.TypeReg}
+	if err := a.tar.WriteHeader(header); err != nil {
+		return fmt.Errorf("erro ao adicionar %s: %w", name, err)
+	}
+	_, err := io.Copy(a.tar, content)
+	return err
+}
+
+func (a *tarArchiveWriter) close() error {
+	if err := a.tar.Close(); err != nil {
+		return err
+	}
+	return a.gzip.Close()
+}
+
+type zipArchiveReader struct {
+	zip *zip.Reader
+}
+
+func (a *zipArchiveReader) each(fn func(name string, content io.Reader) error) error {
+	// Make sure the manifest is visited first regardless of its position.
+	files := append([]*zip.File{}, a.zip.File...)
+	for i, file := range files {
+		if file.Name == archiveManifestName && i > 0 {
+			files[0], files[i] = files[i], files[0]
+			break
+		}
+	}
+
+	for _, file := range files {
+		if file.FileInfo().IsDir() {
+			continue
+		}
+
+		rc, err := file.Open()
+		if err != nil {
+			return fmt.Errorf("erro ao ler %s: %w", file.Name, err)
+		}
+		err = fn(file.Name, rc)
+		rc.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type tarArchiveReader struct {
+	source io.Reader
+}
+
+func (a *tarArchiveReader) each(fn func(name string, content io.Reader) error) error {
+	gz, err := gzip.NewReader(a.source)
+	if err != nil {
+		return fmt.Errorf("erro ao abrir tar.gz: %w", err)
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("erro ao ler tar.gz: %w", err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		if err := fn(header.Name, tr); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package application
+
+import (
+	"path"
+
+	"github.com/sant0x00/downloader-music/internal/domain"
+)
+
+// SetCoverSource enables saving a folder image (e.g. cover.jpg) next to the
+// clips. An empty name disables it.
+func (s *LibraryService) SetCoverSource(covers domain.CoverSource, folderImage string) {
+	s.covers = covers
+	s.folderImage = folderImage
+}
+
+// SaveFolderCovers writes the folder image of every folder holding one of the
+// given clips, using the artwork of the first clip that has one. Existing
+// images are kept.
+func (s *LibraryService) SaveFolderCovers(clipes []domain.ClipeMusical) int {
+	if s.covers == nil || s.folderImage == "" {
+		return 0
+	}
+
+	saved := 0
+	done := make(map[string]bool)
+
+	for _, clipe := range clipes {
+		if len(clipe.Imagens) == 0 {
+			continue
+		}
+
+		imagePath := path.Join(clipe.GetDirectoryPath(), s.folderImage)
+		if done[imagePath] {
+			continue
+		}
+		if s.storage.FileExists(imagePath) {
+			done[imagePath] = true
+			continue
+		}
+
+		cover, err := s.covers.FetchCover(clipe)
+		if err != nil || cover == nil {
+			continue
+		}
+
+		if err := s.writeCover(imagePath, cover); err != nil {
+			s.logger.Error("Erro ao salvar imagem da pasta", err, "arquivo", imagePath)
+			continue
+		}
+
+		done[imagePath] = true
+		saved++
+		s.logger.Info("Imagem da pasta salva", "arquivo", imagePath)
+	}
+
+	return saved
+}
+
+func (s *LibraryService) writeCover(imagePath string, cover *domain.CoverImage) error {
+	out, err := s.storage.CreateFile(imagePath)
+	if err != nil {
+		return err
+	}
+
+	if _, err := out.Write(cover.Data); err != nil {
+		out.Abort()
+		return err
+	}
+
+	return out.Commit()
+}
@@ -0,0 +1,73 @@
+package application
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/sant0x00/downloader-music/internal/domain"
+)
+
+func (s *LibraryService) SetLyricsSource(lyrics domain.LyricsSource) {
+	s.lyrics = lyrics
+}
+
+// SaveLyrics writes <name>.txt next to every downloaded clip of the list, plus
+// <name>.lrc when the lyrics carry timing markers. Existing files are kept.
+func (s *LibraryService) SaveLyrics(clipes []domain.ClipeMusical) (int, error) {
+	if s.lyrics == nil {
+		return 0, nil
+	}
+
+	manifest, err := s.storage.ReadManifest()
+	if err != nil {
+		return 0, err
+	}
+
+	saved := 0
+	for _, clipe := range clipes {
+		entry, exists := manifest.Get(clipe.GetRelativePath())
+		if !exists {
+			continue
+		}
+
+		stem := strings.TrimSuffix(entry.Path, path.Ext(entry.Path))
+		if s.storage.FileExists(stem + ".txt") {
+			continue
+		}
+
+		lyrics, err := s.lyrics.FetchLyrics(clipe)
+		if err != nil {
+			s.logger.Warn("Letra não encontrada", "titulo", clipe.Titulo, "erro", err.Error())
+			continue
+		}
+
+		if err := s.writeText(stem+".txt", domain.StripLyricsTimings(lyrics)+"\n"); err != nil {
+			s.logger.Error("Erro ao salvar letra", err, "arquivo", stem+".txt")
+			continue
+		}
+		if domain.HasLyricsTimings(lyrics) {
+			if err := s.writeText(stem+".lrc", strings.TrimSpace(lyrics)+"\n"); err != nil {
+				s.logger.Error("Erro ao salvar letra sincronizada", err, "arquivo", stem+".lrc")
+			}
+		}
+
+		saved++
+		s.logger.Debug("Letra salva", "titulo", clipe.Titulo, "arquivo", stem+".txt")
+	}
+
+	s.logger.Info("Letras salvas", "total", saved)
+	return saved, nil
+}
+
+func (s *LibraryService) writeText(filePath, content string) error {
+	out, err := s.storage.CreateFile(filePath)
+	if err != nil {
+		return err
+	}
+	if _, err := out.Write([]byte(content)); err != nil {
+		out.Abort()
+		return fmt.Errorf("erro ao escrever %s: %w", filePath, err)
+	}
+	return out.Commit()
+}
@@ -0,0 +1,310 @@
+package application
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sant0x00/downloader-music/internal/domain"
+)
+
+const (
+	libraryPlaylistName = "biblioteca"
+	newPlaylistName     = "novos"
+	pairPlaylistName    = "bilingue"
+)
+
+type PlaylistFormat string
+
+const (
+	PlaylistFormatM3U8 PlaylistFormat = "m3u8"
+	PlaylistFormatXSPF PlaylistFormat = "xspf"
+	PlaylistFormatPLS  PlaylistFormat = "pls"
+)
+
+func ParsePlaylistFormat(value string) (PlaylistFormat, error) {
+	switch format := PlaylistFormat(strings.ToLower(strings.TrimSpace(value))); format {
+	case PlaylistFormatM3U8, PlaylistFormatXSPF, PlaylistFormatPLS:
+		return format, nil
+	case "m3u":
+		return PlaylistFormatM3U8, nil
+	default:
+		return "", fmt.Errorf("formato de playlist inválido: %s (use m3u8, xspf ou pls)", value)
+	}
+}
+
+type PlaylistOptions struct {
+	Formats  []PlaylistFormat
+	PerYear  bool
+	Library  bool
+	NewInRun bool
+	// PairLanguages, when set, adds a playlist alternating the versions of
+	// each song in these two languages.
+	PairLanguages []string
+}
+
+// playlistItem is one track, with its path relative to the playlist file.
+// Duration is in seconds; -1 means unknown.
+type playlistItem struct {
+	Path     string
+	Title    string
+	Duration int
+}
+
+func (s *LibraryService) SetPlaylistOptions(options PlaylistOptions) {
+	s.playlists = options
+}
+
+// GeneratePlaylists rewrites the playlists of the library: one per folder,
+// one for the whole library and one with the clips downloaded since runStart.
+// Paths are relative to each playlist so the library stays portable.
+func (s *LibraryService) GeneratePlaylists(runStart time.Time) ([]string, error) {
+	if len(s.playlists.Formats) == 0 {
+		return nil, nil
+	}
+
+	manifest, err := s.storage.ReadManifest()
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := s.storage.ListFiles()
+	if err != nil {
+		return nil, err
+	}
+	present := make(map[string]bool, len(files))
+	for _, file := range files {
+		present[file.Path] = true
+	}
+
+	var entries []domain.ManifestEntry
+	for _, entry := range manifest.SortedEntries() {
+		if present[entry.Path] {
+			entries = append(entries, entry)
+		}
+	}
+
+	var written []string
+	write := func(name, title string, items []playlistItem) error {
+		for _, format := range s.playlists.Formats {
+			playlistPath := name + "." + string(format)
+			if err := s.writePlaylist(playlistPath, title, format, items); err != nil {
+				return err
+			}
+			written = append(written, playlistPath)
+		}
+		return nil
+	}
+
+	if s.playlists.PerYear {
+		folders := make(map[string][]domain.ManifestEntry)
+		for _, entry := range entries {
+			folder := path.Dir(entry.Path)
+			if folder == "." {
+				continue
+			}
+			folders[folder] = append(folders[folder], entry)
+		}
+
+		names := make([]string, 0, len(folders))
+		for folder := range folders {
+			names = append(names, folder)
+		}
+		sort.Strings(names)
+
+		for _, folder := range names {
+			folderEntries := folders[folder]
+			sortByTrack(folderEntries)
+			if err := write(path.Join(folder, path.Base(folder)), path.Base(folder), playlistItems(folderEntries, folder)); err != nil {
+				return written, err
+			}
+		}
+	}
+
+	if s.playlists.Library {
+		if err := write(libraryPlaylistName, "Clipes JW.org", playlistItems(entries, ".")); err != nil {
+			return written, err
+		}
+	}
+
+	if s.playlists.NewInRun {
+		var recent []domain.ManifestEntry
+		for _, entry := range entries {
+			if !entry.DownloadedAt.Before(runStart) {
+				recent = append(recent, entry)
+			}
+		}
+		if err := write(newPlaylistName, "Novos clipes", playlistItems(recent, ".")); err != nil {
+			return written, err
+		}
+	}
+
+	if len(s.playlists.PairLanguages) == 2 {
+		if err := write(pairPlaylistName, "Clipes bilíngues", playlistItems(pairedEntries(entries, s.playlists.PairLanguages), ".")); err != nil {
+			return written, err
+		}
+	}
+
+	s.logger.Info("Playlists geradas", "total", len(written))
+	return written, nil
+}
+
+func (s *LibraryService) writePlaylist(playlistPath, title string, format PlaylistFormat, items []playlistItem) error {
+	var content []byte
+	var err error
+
+	switch format {
+	case PlaylistFormatM3U8:
+		content = encodeM3U8(items)
+	case PlaylistFormatXSPF:
+		content, err = encodeXSPF(title, items)
+	case PlaylistFormatPLS:
+		content = encodePLS(items)
+	default:
+		err = fmt.Errorf("formato de playlist inválido: %s", format)
+	}
+	if err != nil {
+		return err
+	}
+
+	out, err := s.storage.CreateFile(playlistPath)
+	if err != nil {
+		return err
+	}
+	if _, err := out.Write(content); err != nil {
+		out.Abort()
+		return fmt.Errorf("erro ao escrever playlist %s: %w", playlistPath, err)
+	}
+	return out.Commit()
+}
+
+func sortByTrack(entries []domain.ManifestEntry) {
+	sort.SliceStable(entries, func(i, j int) bool {
+		a, b := entries[i], entries[j]
+		if (a.Faixa > 0) != (b.Faixa > 0) {
+			return a.Faixa > 0
+		}
+		if a.Faixa != b.Faixa {
+			return a.Faixa < b.Faixa
+		}
+		return a.Titulo < b.Titulo
+	})
+}
+
+// pairedEntries orders the songs by track, each one followed by its version
+// in the second language.
+func pairedEntries(entries []domain.ManifestEntry, languages []string) []domain.ManifestEntry {
+	versions := make(map[string]map[string]domain.ManifestEntry)
+	var songs []domain.ManifestEntry
+	for _, entry := range entries {
+		if entry.Chave == "" {
+			continue
+		}
+		if versions[entry.Chave] == nil {
+			versions[entry.Chave] = make(map[string]domain.ManifestEntry)
+			songs = append(songs, entry)
+		}
+		versions[entry.Chave][entry.GetIdioma()] = entry
+	}
+	sortByTrack(songs)
+
+	var paired []domain.ManifestEntry
+	for _, song := range songs {
+		for _, language := range languages {
+			if entry, exists := versions[song.Chave][language]; exists {
+				paired = append(paired, entry)
+			}
+		}
+	}
+	return paired
+}
+
+func playlistItems(entries []domain.ManifestEntry, baseDir string) []playlistItem {
+	items := make([]playlistItem, 0, len(entries))
+	for _, entry := range entries {
+		relPath := entry.Path
+		if baseDir != "." {
+			relPath = strings.TrimPrefix(entry.Path, baseDir+"/")
+		}
+
+		title := entry.Titulo
+		if title == "" {
+			title = strings.TrimSuffix(path.Base(entry.Path), path.Ext(entry.Path))
+		}
+
+		duration := -1
+		if entry.Duracao > 0 {
+			duration = int(entry.Duracao + 0.5)
+		}
+
+		items = append(items, playlistItem{Path: relPath, Title: title, Duration: duration})
+	}
+	return items
+}
+
+func encodeM3U8(items []playlistItem) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("#EXTM3U\n")
+	for _, item := range items {
+		fmt.Fprintf(&buf, "#EXTINF:%d,%s\n%s\n", item.Duration, oneLine(item.Title), item.Path)
+	}
+	return buf.Bytes()
+}
+
+func encodePLS(items []playlistItem) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("[playlist]\n")
+	for i, item := range items {
+		fmt.Fprintf(&buf, "File%d=%s\nTitle%d=%s\nLength%d=%d\n", i+1, item.Path, i+1, oneLine(item.Title), i+1, item.Duration)
+	}
+	fmt.Fprintf(&buf, "NumberOfEntries=%d\nVersion=2\n", len(items))
+	return buf.Bytes()
+}
+
+type xspfPlaylist struct {
+	XMLName xml.Name    `xml:"playlist"`
+	Version string      `xml:"version,attr"`
+	XMLNS   string      `xml:"xmlns,attr"`
+	Title   string      `xml:"title"`
+	Tracks  []xspfTrack `xml:"trackList>track"`
+}
+
+type xspfTrack struct {
+	Location string `xml:"location"`
+	Title    string `xml:"title"`
+	Duration int    `xml:"duration,omitempty"`
+}
+
+func encodeXSPF(title string, items []playlistItem) ([]byte, error) {
+	playlist := xspfPlaylist{Version: "1", XMLNS: "http://xspf.org/ns/0/", Title: title}
+	for _, item := range items {
+		track := xspfTrack{Location: escapeRelativeURI(item.Path), Title: item.Title}
+		if item.Duration > 0 {
+			track.Duration = item.Duration * 1000 // XSPF durations are in milliseconds
+		}
+		playlist.Tracks = append(playlist.Tracks, track)
+	}
+
+	data, err := xml.MarshalIndent(playlist, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("erro ao codificar XSPF: %w", err)
+	}
+	return append([]byte(xml.Header), append(data, '\n')...), nil
+}
+
+func escapeRelativeURI(relPath string) string {
+	segments := strings.Split(relPath, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+func oneLine(value string) string {
+	return strings.Join(strings.Fields(value), " ")
+}
@@ -0,0 +1,203 @@
+package application
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sant0x00/downloader-music/internal/domain"
+)
+
+type PrunePolicy string
+
+const (
+	PrunePolicyArchive PrunePolicy = "archive"
+	PrunePolicyDelete  PrunePolicy = "delete"
+)
+
+func ParsePrunePolicy(value string) (PrunePolicy, error) {
+	switch policy := PrunePolicy(strings.ToLower(strings.TrimSpace(value))); policy {
+	case "":
+		return PrunePolicyArchive, nil
+	case PrunePolicyArchive, PrunePolicyDelete:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("política de limpeza inválida: %s", value)
+	}
+}
+
+// minPruneCatalogShare is the smallest catalog, relative to the library files
+// it covers, that prune trusts without --force. A much smaller catalog is
+// more likely a broken listing than clips taken down.
+const minPruneCatalogShare = 0.5
+
+type PruneAction struct {
+	Path   string
+	Target string
+	Titulo string
+	// Sidecars are the lyrics, covers and other files named after the
+	// pruned one, which follow it to the archive or are deleted with it.
+	Sidecars []PruneAction
+}
+
+// Prune removes from the library every file that is no longer part of the
+// upstream catalog, archiving or deleting it according to the policy. Files
+// are matched by their path in the library. An incomplete catalog, or one
+// much smaller than the library, is only pruned against with force.
+func (s *LibraryService) Prune(run RunCatalog, policy PrunePolicy, dryRun, force bool) ([]PruneAction, error) {
+	catalog := run.Clipes
+	if len(catalog) == 0 {
+		return nil, fmt.Errorf("catálogo vazio, limpeza cancelada por segurança")
+	}
+	if run.Incompleto && !force {
+		return nil, fmt.Errorf("catálogo incompleto, limpeza cancelada por segurança (use --force para limpar mesmo assim)")
+	}
+
+	s.logger.Info("Comparando biblioteca com o catálogo", "clipes_catalogo", len(catalog), "politica", policy, "simulacao", dryRun)
+
+	files, err := s.storage.ListFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	manifest, err := s.storage.ReadManifest()
+	if err != nil {
+		return nil, err
+	}
+
+	expected := make(map[string]bool, len(catalog))
+	languages := make(map[string]bool)
+	publications := make(map[string]bool)
+	for _, clipe := range catalog {
+		expected[clipe.GetRelativePath()] = true
+		languages[clipe.GetIdioma()] = true
+		publications[clipe.GetPublicacao()] = true
+	}
+	for duplicate, alias := range manifest.Aliases {
+		if expected[duplicate] {
+			expected[alias.Canonical] = true
+		}
+	}
+
+	var actions []PruneAction
+	inScope := 0
+	for _, file := range files {
+		// Only the languages and publication fetched in this run are mirrored.
+		entry, known := manifest.Get(file.Path)
+		if !languages[fileLanguage(file.Path, entry, known)] {
+			continue
+		}
+		if !publications[filePublication(entry, known)] {
+			continue
+		}
+
+		inScope++
+		if expected[file.Path] {
+			continue
+		}
+
+		action := PruneAction{Path: file.Path}
+		if known {
+			action.Titulo = entry.Titulo
+		}
+		sidecars, err := s.storage.FindSidecars(file.Path)
+		if err != nil {
+			s.logger.Warn("Não foi possível listar arquivos auxiliares", "arquivo", file.Path, "erro", err.Error())
+		}
+		for _, sidecar := range sidecars {
+			action.Sidecars = append(action.Sidecars, PruneAction{Path: sidecar})
+		}
+		actions = append(actions, action)
+	}
+
+	if !force && float64(len(catalog)) < minPruneCatalogShare*float64(inScope) {
+		return nil, fmt.Errorf("catálogo com %d clipes para %d arquivos na biblioteca, limpeza cancelada por segurança (use --force para limpar mesmo assim)", len(catalog), inScope)
+	}
+
+	sort.Slice(actions, func(i, j int) bool {
+		return actions[i].Path < actions[j].Path
+	})
+
+	for i := range actions {
+		if err := s.applyPrune(&actions[i], policy, dryRun); err != nil {
+			return actions[:i], err
+		}
+	}
+
+	s.logger.Info("Limpeza da biblioteca concluída", "removidos", len(actions), "simulacao", dryRun)
+	return actions, nil
+}
+
+func (s *LibraryService) applyPrune(action *PruneAction, policy PrunePolicy, dryRun bool) error {
+	if err := s.pruneFile(action, policy, dryRun); err != nil {
+		return err
+	}
+
+	if !dryRun {
+		err := s.storage.UpdateManifest(func(m *domain.Manifest) {
+			m.Remove(action.Path)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	for i := range action.Sidecars {
+		if err := s.pruneFile(&action.Sidecars[i], policy, dryRun); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// pruneFile archives or deletes a single file and logs it.
+func (s *LibraryService) pruneFile(action *PruneAction, policy PrunePolicy, dryRun bool) error {
+	logEntry := domain.LibraryAction{
+		Time:   time.Now(),
+		Action: "prune-" + string(policy),
+		Path:   action.Path,
+		Reason: "ausente no catálogo",
+		DryRun: dryRun,
+	}
+
+	if !dryRun {
+		switch policy {
+		case PrunePolicyDelete:
+			if err := s.storage.RemoveFile(action.Path); err != nil {
+				return err
+			}
+		default:
+			target, err := s.storage.ArchiveFile(action.Path)
+			if err != nil {
+				return err
+			}
+			action.Target = target
+			logEntry.Target = target
+		}
+	}
+
+	s.logger.Info("Arquivo ausente no catálogo", "acao", logEntry.Action, "arquivo", action.Path, "destino", action.Target, "simulacao", dryRun)
+	return s.storage.AppendActionLog(logEntry)
+}
+
+// fileLanguage guesses the language of a library file from its manifest
+// entry or, for unknown files, from a <language>/<year>/<file> layout.
+func fileLanguage(filePath string, entry domain.ManifestEntry, known bool) string {
+	if known {
+		return entry.GetIdioma()
+	}
+	if parts := strings.Split(filePath, "/"); len(parts) == 3 {
+		return parts[0]
+	}
+	return domain.DefaultLanguage
+}
+
+// filePublication returns the publication of a library file. Files missing
+// from the manifest predate other publications, so they are music videos.
+func filePublication(entry domain.ManifestEntry, known bool) string {
+	if known {
+		return entry.GetPublicacao()
+	}
+	return domain.DefaultPublication
+}
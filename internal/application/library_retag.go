@@ -0,0 +1,81 @@
+package application
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/sant0x00/downloader-music/internal/domain"
+)
+
+// Retag rewrites the tags of every file in the manifest using the current
+// tagging rules.
+func (s *LibraryService) Retag(tagger domain.AudioTagger) (int, error) {
+	manifest, err := s.storage.ReadManifest()
+	if err != nil {
+		return 0, err
+	}
+
+	s.logger.Info("Reaplicando tags na biblioteca", "total", len(manifest.Entries))
+
+	retagged := 0
+	for _, entry := range manifest.SortedEntries() {
+		clipe := entry.ToClipe()
+		if !tagger.Supports(clipe) || !s.storage.FileExists(entry.Path) {
+			continue
+		}
+
+		updated, err := s.retagFile(tagger, entry)
+		if err != nil {
+			s.logger.Error("Erro ao reaplicar tags", err, "arquivo", entry.Path)
+			continue
+		}
+
+		if err := s.storage.UpdateManifest(func(m *domain.Manifest) { m.Put(updated) }); err != nil {
+			return retagged, err
+		}
+
+		s.logger.Debug("Tags reaplicadas", "arquivo", entry.Path)
+		retagged++
+	}
+
+	s.logger.Info("Tags reaplicadas", "total", retagged)
+	return retagged, nil
+}
+
+func (s *LibraryService) retagFile(tagger domain.AudioTagger, entry domain.ManifestEntry) (domain.ManifestEntry, error) {
+	src, err := s.storage.OpenFile(entry.Path)
+	if err != nil {
+		return entry, err
+	}
+	defer src.Close()
+
+	out, err := s.storage.CreateFile(entry.Path)
+	if err != nil {
+		return entry, err
+	}
+
+	hash := domain.NewPayloadHash()
+	counter := &byteCounter{}
+	if err := tagger.Tag(io.MultiWriter(out, hash, counter), src, entry.ToClipe()); err != nil {
+		out.Abort()
+		return entry, fmt.Errorf("erro ao gravar tags: %w", err)
+	}
+
+	if err := out.Commit(); err != nil {
+		return entry, err
+	}
+
+	entry.Size = counter.n
+	entry.SHA256 = hash.Sum()
+	entry.Tagged = true
+	return entry, nil
+}
+
+type byteCounter struct {
+	n int64
+}
+
+func (c *byteCounter) Write(p []byte) (int, error) {
+	c.n += int64(len(p))
+	return len(p), nil
+}
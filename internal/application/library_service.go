@@ -0,0 +1,175 @@
+package application
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sant0x00/downloader-music/internal/domain"
+)
+
+type DedupePolicy string
+
+const (
+	DedupePolicyReport   DedupePolicy = "report"
+	DedupePolicyHardlink DedupePolicy = "hardlink"
+	DedupePolicyRemove   DedupePolicy = "remove"
+)
+
+func ParseDedupePolicy(value string) (DedupePolicy, error) {
+	switch policy := DedupePolicy(strings.ToLower(strings.TrimSpace(value))); policy {
+	case "":
+		return DedupePolicyReport, nil
+	case DedupePolicyReport, DedupePolicyHardlink, DedupePolicyRemove:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("política de deduplicação inválida: %s", value)
+	}
+}
+
+type DuplicateGroup struct {
+	SHA256     string
+	Size       int64
+	Canonical  string
+	Duplicates []string
+}
+
+type LibraryService struct {
+	storage    domain.LibraryStorage
+	downloader domain.DownloadService
+	catalog    domain.CatalogSource
+	logger     domain.Logger
+
+	covers      domain.CoverSource
+	folderImage string
+	playlists   PlaylistOptions
+	inspector   domain.AudioInspector
+	lyrics      domain.LyricsSource
+}
+
+func NewLibraryService(
+	storage domain.LibraryStorage,
+	downloader domain.DownloadService,
+	catalog domain.CatalogSource,
+	logger domain.Logger,
+) *LibraryService {
+	return &LibraryService{
+		storage:    storage,
+		downloader: downloader,
+		catalog:    catalog,
+		logger:     logger,
+	}
+}
+
+func (s *LibraryService) Dedupe(policy DedupePolicy) ([]DuplicateGroup, error) {
+	s.logger.Info("Procurando arquivos duplicados na biblioteca", "politica", policy)
+
+	files, err := s.storage.ListFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	manifest, err := s.storage.ReadManifest()
+	if err != nil {
+		return nil, err
+	}
+
+	// The hash leaves the ID3 tags out, so re-releases with other metadata
+	// match even though their sizes differ.
+	byHash := make(map[string][]domain.LibraryFile)
+	for _, file := range files {
+		checksum, err := s.storage.HashFile(file.Path)
+		if err != nil {
+			s.logger.Error("Erro ao calcular hash", err, "arquivo", file.Path)
+			continue
+		}
+		byHash[checksum] = append(byHash[checksum], file)
+	}
+
+	var groups []DuplicateGroup
+	for checksum, identical := range byHash {
+		if len(identical) < 2 {
+			continue
+		}
+
+		// Keep the oldest copy, usually the original release.
+		sort.Slice(identical, func(i, j int) bool {
+			left, right := s.sortKey(manifest, identical[i]), s.sortKey(manifest, identical[j])
+			if left.Equal(right) {
+				return identical[i].Path < identical[j].Path
+			}
+			return left.Before(right)
+		})
+
+		group := DuplicateGroup{
+			SHA256:    checksum,
+			Size:      identical[0].Size,
+			Canonical: identical[0].Path,
+		}
+		for _, duplicate := range identical[1:] {
+			group.Duplicates = append(group.Duplicates, duplicate.Path)
+		}
+		groups = append(groups, group)
+	}
+
+	sort.Slice(groups, func(i, j int) bool {
+		return groups[i].Canonical < groups[j].Canonical
+	})
+
+	s.logger.Info("Busca por duplicados concluída", "grupos", len(groups))
+
+	if policy == DedupePolicyReport || len(groups) == 0 {
+		return groups, nil
+	}
+
+	for _, group := range groups {
+		if err := s.applyDedupe(policy, group, manifest); err != nil {
+			return groups, err
+		}
+	}
+
+	return groups, nil
+}
+
+func (s *LibraryService) applyDedupe(policy DedupePolicy, group DuplicateGroup, manifest *domain.Manifest) error {
+	var applied []string
+
+	for _, duplicate := range group.Duplicates {
+		var err error
+		switch policy {
+		case DedupePolicyHardlink:
+			err = s.storage.LinkDuplicate(group.Canonical, duplicate)
+		case DedupePolicyRemove:
+			err = s.storage.RemoveFile(duplicate)
+		}
+		if err != nil {
+			s.logger.Error("Erro ao tratar duplicata", err, "arquivo", duplicate, "canonico", group.Canonical)
+			continue
+		}
+
+		s.logger.Info("Duplicata tratada", "politica", policy, "arquivo", duplicate, "canonico", group.Canonical)
+		applied = append(applied, duplicate)
+	}
+
+	return s.storage.UpdateManifest(func(m *domain.Manifest) {
+		for _, duplicate := range applied {
+			titulo := ""
+			if entry, exists := manifest.Get(duplicate); exists {
+				titulo = entry.Titulo
+			}
+
+			m.AddAlias(duplicate, titulo, group.Canonical)
+			if policy == DedupePolicyRemove {
+				m.Remove(duplicate)
+			}
+		}
+	})
+}
+
+func (s *LibraryService) sortKey(manifest *domain.Manifest, file domain.LibraryFile) time.Time {
+	if entry, exists := manifest.Get(file.Path); exists && !entry.DownloadedAt.IsZero() {
+		return entry.DownloadedAt
+	}
+	return file.ModTime
+}
@@ -0,0 +1,291 @@
+package application
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/sant0x00/downloader-music/internal/domain"
+)
+
+type VerifyIssueKind string
+
+const (
+	VerifyIssueMissing   VerifyIssueKind = "missing"
+	VerifyIssueTruncated VerifyIssueKind = "truncated"
+	VerifyIssueCorrupted VerifyIssueKind = "corrupted"
+	VerifyIssueNotAudio  VerifyIssueKind = "not_audio"
+	VerifyIssueUnknown   VerifyIssueKind = "unknown"
+)
+
+type VerifyIssue struct {
+	Path   string
+	Kind   VerifyIssueKind
+	Detail string
+	Entry  *domain.ManifestEntry
+}
+
+type VerifyReport struct {
+	Checked int
+	Issues  []VerifyIssue
+}
+
+func (r VerifyReport) Repairable() []VerifyIssue {
+	var repairable []VerifyIssue
+	for _, issue := range r.Issues {
+		if issue.Kind != VerifyIssueUnknown && issue.Entry != nil && issue.Entry.URLDownload != "" {
+			repairable = append(repairable, issue)
+		}
+	}
+	return repairable
+}
+
+// SetInspector enables structural validation of the audio during Verify.
+func (s *LibraryService) SetInspector(inspector domain.AudioInspector) {
+	s.inspector = inspector
+}
+
+func (s *LibraryService) Verify() (*VerifyReport, error) {
+	s.logger.Info("Verificando integridade da biblioteca")
+
+	files, err := s.storage.ListFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	manifest, err := s.storage.ReadManifest()
+	if err != nil {
+		return nil, err
+	}
+
+	remoteByURL := s.remoteFilesByURL()
+
+	report := &VerifyReport{}
+	inspected := make(map[string]domain.AudioInfo)
+	onDisk := make(map[string]domain.LibraryFile, len(files))
+	for _, file := range files {
+		onDisk[file.Path] = file
+	}
+
+	for _, entry := range manifest.SortedEntries() {
+		file, exists := onDisk[entry.Path]
+		if !exists {
+			report.Issues = append(report.Issues, VerifyIssue{
+				Path:   entry.Path,
+				Kind:   VerifyIssueMissing,
+				Detail: "arquivo registrado no manifesto não existe",
+				Entry:  &entry,
+			})
+			continue
+		}
+
+		report.Checked++
+		issue, info := s.verifyFile(file, &entry, remoteByURL[entry.URLDownload])
+		if issue != nil {
+			report.Issues = append(report.Issues, *issue)
+		} else if info != nil && (entry.Duracao == 0 || entry.Bitrate == 0) {
+			inspected[entry.Path] = *info
+		}
+	}
+
+	for _, file := range files {
+		if _, known := manifest.Get(file.Path); known {
+			continue
+		}
+		if _, isAlias := manifest.ResolveAlias(file.Path); isAlias {
+			continue
+		}
+
+		report.Checked++
+		if issue, _ := s.verifyFile(file, nil, nil); issue != nil {
+			report.Issues = append(report.Issues, *issue)
+			continue
+		}
+		report.Issues = append(report.Issues, VerifyIssue{
+			Path:   file.Path,
+			Kind:   VerifyIssueUnknown,
+			Detail: "arquivo não registrado no manifesto",
+		})
+	}
+
+	// Libraries downloaded before inspection existed get their durations now.
+	if len(inspected) > 0 {
+		err := s.storage.UpdateManifest(func(m *domain.Manifest) {
+			for entryPath, info := range inspected {
+				if entry, exists := m.Get(entryPath); exists {
+					entry.Duracao = info.Duration
+					entry.Bitrate = info.Bitrate
+					m.Put(entry)
+				}
+			}
+		})
+		if err != nil {
+			s.logger.Warn("Não foi possível registrar as durações no manifesto", "erro", err.Error())
+		}
+	}
+
+	sort.SliceStable(report.Issues, func(i, j int) bool {
+		return report.Issues[i].Path < report.Issues[j].Path
+	})
+
+	s.logger.Info("Verificação da biblioteca concluída", "verificados", report.Checked, "problemas", len(report.Issues))
+	return report, nil
+}
+
+func (s *LibraryService) Repair(report *VerifyReport) (int, error) {
+	if s.downloader == nil {
+		return 0, fmt.Errorf("reparo indisponível: downloader não configurado")
+	}
+
+	repairable := report.Repairable()
+	if len(repairable) == 0 {
+		return 0, nil
+	}
+
+	s.logger.Info("Reparando itens da biblioteca", "total", len(repairable))
+
+	// Damaged files are left in place until their replacement is complete,
+	// so a failed download does not lose them.
+	var clipes []domain.ClipeMusical
+	for _, issue := range repairable {
+		clipes = append(clipes, issue.Entry.ToClipe())
+	}
+
+	if err := s.downloader.ReplaceBatch(clipes, s.storage.GetOutputDirectory()); err != nil {
+		return len(clipes), fmt.Errorf("erro durante reparo: %w", err)
+	}
+
+	return len(clipes), nil
+}
+
+func (s *LibraryService) verifyFile(file domain.LibraryFile, entry *domain.ManifestEntry, remote *domain.RemoteFile) (*VerifyIssue, *domain.AudioInfo) {
+	reader, err := s.storage.OpenFile(file.Path)
+	if err != nil {
+		return &VerifyIssue{Path: file.Path, Kind: VerifyIssueMissing, Detail: err.Error(), Entry: entry}, nil
+	}
+	defer reader.Close()
+
+	sha := domain.NewPayloadHash()
+	sum := md5.New()
+	header := &headerBuffer{limit: 512}
+	writers := []io.Writer{sha, sum, header}
+
+	var inspection domain.AudioInspection
+	if s.inspector != nil && s.inspector.Supports(file.Path) {
+		inspection = s.inspector.NewInspection()
+		writers = append(writers, inspection)
+	}
+
+	if _, err := io.Copy(io.MultiWriter(writers...), reader); err != nil {
+		return &VerifyIssue{Path: file.Path, Kind: VerifyIssueCorrupted, Detail: err.Error(), Entry: entry}, nil
+	}
+
+	if reason := sniffNonAudio(header.Bytes()); reason != "" {
+		return &VerifyIssue{Path: file.Path, Kind: VerifyIssueNotAudio, Detail: reason, Entry: entry}, nil
+	}
+
+	var info *domain.AudioInfo
+	if inspection != nil {
+		result, err := inspection.Finish()
+		switch {
+		case errors.Is(err, domain.ErrNotAudio):
+			return &VerifyIssue{Path: file.Path, Kind: VerifyIssueNotAudio, Detail: err.Error(), Entry: entry}, nil
+		case errors.Is(err, domain.ErrTruncatedAudio):
+			return &VerifyIssue{Path: file.Path, Kind: VerifyIssueTruncated, Detail: err.Error(), Entry: entry}, nil
+		case err != nil:
+			return &VerifyIssue{Path: file.Path, Kind: VerifyIssueCorrupted, Detail: err.Error(), Entry: entry}, nil
+		}
+		info = &result
+	}
+
+	// Tagging rewrites the file, so the API size and checksum only apply
+	// to untouched downloads.
+	if entry != nil && entry.Tagged {
+		remote = nil
+	}
+
+	if remote != nil && remote.Size > 0 && file.Size != remote.Size {
+		return sizeIssue(file, entry, remote.Size, "API"), nil
+	}
+	if entry != nil && entry.Size > 0 && file.Size != entry.Size {
+		return sizeIssue(file, entry, entry.Size, "manifesto"), nil
+	}
+
+	if remote != nil && remote.Checksum != "" && !strings.EqualFold(hex.EncodeToString(sum.Sum(nil)), remote.Checksum) {
+		return &VerifyIssue{Path: file.Path, Kind: VerifyIssueCorrupted, Detail: "checksum MD5 difere da API", Entry: entry}, nil
+	}
+	if entry != nil && entry.SHA256 != "" && sha.Sum() != entry.SHA256 {
+		return &VerifyIssue{Path: file.Path, Kind: VerifyIssueCorrupted, Detail: "SHA-256 difere do manifesto", Entry: entry}, nil
+	}
+
+	return nil, info
+}
+
+func (s *LibraryService) remoteFilesByURL() map[string]*domain.RemoteFile {
+	remoteByURL := make(map[string]*domain.RemoteFile)
+	if s.catalog == nil {
+		return remoteByURL
+	}
+
+	remoteFiles, err := s.catalog.FetchRemoteFiles()
+	if err != nil {
+		s.logger.Warn("Não foi possível obter checksums da API, usando apenas o manifesto", "erro", err.Error())
+		return remoteByURL
+	}
+
+	for i := range remoteFiles {
+		remoteByURL[remoteFiles[i].URLDownload] = &remoteFiles[i]
+	}
+	return remoteByURL
+}
+
+func sizeIssue(file domain.LibraryFile, entry *domain.ManifestEntry, expected int64, source string) *VerifyIssue {
+	kind := VerifyIssueCorrupted
+	if file.Size < expected {
+		kind = VerifyIssueTruncated
+	}
+	return &VerifyIssue{
+		Path:   file.Path,
+		Kind:   kind,
+		Detail: fmt.Sprintf("tamanho %d bytes, esperado %d (%s)", file.Size, expected, source),
+		Entry:  entry,
+	}
+}
+
+// sniffNonAudio returns a reason when the header is clearly not audio, such
+// as an HTML error page served with status 200.
+func sniffNonAudio(header []byte) string {
+	trimmed := bytes.TrimSpace(header)
+	if len(trimmed) == 0 {
+		return "arquivo vazio"
+	}
+
+	lower := bytes.ToLower(trimmed)
+	for _, marker := range []string{"<!doctype", "<html", "<?xml", "{"} {
+		if bytes.HasPrefix(lower, []byte(marker)) {
+			return "conteúdo de texto/HTML salvo como áudio"
+		}
+	}
+
+	return ""
+}
+
+type headerBuffer struct {
+	bytes.Buffer
+	limit int
+}
+
+func (h *headerBuffer) Write(p []byte) (int, error) {
+	if remaining := h.limit - h.Len(); remaining > 0 {
+		if len(p) < remaining {
+			remaining = len(p)
+		}
+		h.Buffer.Write(p[:remaining])
+	}
+	return len(p), nil
+}
@@ -0,0 +1,113 @@
+package application
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/sant0x00/downloader-music/internal/domain"
+	"golang.org/x/text/unicode/norm"
+)
+
+// TitleMatch is a catalog clip found by a title search. Distance is the edit
+// distance between the normalized query and title, 0 for exact matches and
+// for titles containing the query.
+type TitleMatch struct {
+	Clipe    domain.ClipeMusical
+	Exact    bool
+	Contains bool
+	Distance int
+}
+
+// searchTitles ranks the clips whose title resembles the query, ignoring
+// case, accents and punctuation: exact matches first, then titles containing
+// the query, then the closest by edit distance.
+func searchTitles(clipes []domain.ClipeMusical, query string) []TitleMatch {
+	normalizedQuery := normalizeSearchText(query)
+	if normalizedQuery == "" {
+		return nil
+	}
+
+	// Typos are tolerated in proportion to the length of the query.
+	maxDistance := utf8.RuneCountInString(normalizedQuery) / 3
+	if maxDistance < 2 {
+		maxDistance = 2
+	}
+
+	var matches []TitleMatch
+	for _, clipe := range clipes {
+		title := normalizeSearchText(clipe.Titulo)
+		match := TitleMatch{Clipe: clipe}
+
+		switch {
+		case title == normalizedQuery:
+			match.Exact = true
+		case strings.Contains(title, normalizedQuery):
+			match.Contains = true
+		default:
+			match.Distance = editDistance(normalizedQuery, title)
+			if match.Distance > maxDistance {
+				continue
+			}
+		}
+		matches = append(matches, match)
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		a, b := matches[i], matches[j]
+		if a.Exact != b.Exact {
+			return a.Exact
+		}
+		if a.Contains != b.Contains {
+			return a.Contains
+		}
+		if a.Distance != b.Distance {
+			return a.Distance < b.Distance
+		}
+		return len(a.Clipe.Titulo) < len(b.Clipe.Titulo)
+	})
+
+	return matches
+}
+
+// normalizeSearchText reduces a text to lowercase words without accents,
+// separated by single spaces. Letters of any script are kept.
+func normalizeSearchText(text string) string {
+	var b strings.Builder
+	for _, char := range norm.NFD.String(text) {
+		switch {
+		case unicode.Is(unicode.Mn, char), char == '\'', char == '’':
+			// Accents and apostrophes are dropped without splitting the word.
+		case unicode.IsLetter(char), unicode.IsDigit(char):
+			b.WriteRune(unicode.ToLower(char))
+		default:
+			b.WriteRune(' ')
+		}
+	}
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+// editDistance is the Levenshtein distance between two strings.
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	previous := make([]int, len(rb)+1)
+	current := make([]int, len(rb)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		current[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+
+	return previous[len(rb)]
+}
@@ -0,0 +1,68 @@
+package application
+
+import (
+	"testing"
+
+	"github.com/sant0x00/downloader-music/internal/domain"
+)
+
+func TestNormalizeSearchText(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"Canção de Louvor", "cancao de louvor"},
+		{"  CANÇÃO,   de louvor!  ", "cancao de louvor"},
+		{"Jehovah's Way", "jehovahs way"},
+		{"Песня о Радости", "песня о радости"},
+		{"賛美の歌", "賛美の歌"},
+		{"🎵 !!", ""},
+	}
+
+	for _, tt := range tests {
+		if got := normalizeSearchText(tt.text); got != tt.want {
+			t.Errorf("normalizeSearchText(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestSearchTitles(t *testing.T) {
+	clipes := []domain.ClipeMusical{
+		{Titulo: "Canção de Louvor"},
+		{Titulo: "Canção de Louvor (Ao Vivo)"},
+		{Titulo: "Песня о Радости"},
+		{Titulo: "Alegria"},
+	}
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"cancao de louvor", []string{"Canção de Louvor", "Canção de Louvor (Ao Vivo)"}},
+		{"ПЕСНЯ", []string{"Песня о Радости"}},
+		{"alegira", []string{"Alegria"}},
+		{"inexistente", nil},
+	}
+
+	for _, tt := range tests {
+		matches := searchTitles(clipes, tt.query)
+		var got []string
+		for _, match := range matches {
+			got = append(got, match.Clipe.Titulo)
+		}
+		if len(got) != len(tt.want) {
+			t.Errorf("searchTitles(%q) = %q, want %q", tt.query, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("searchTitles(%q) = %q, want %q", tt.query, got, tt.want)
+				break
+			}
+		}
+	}
+
+	if matches := searchTitles(clipes, "Canção de Louvor"); len(matches) == 0 || !matches[0].Exact {
+		t.Errorf("exact title not ranked first: %+v", matches)
+	}
+}
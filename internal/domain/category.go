@@ -0,0 +1,74 @@
+package domain
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// CategoryRule puts in Categoria the clips whose fields match all of its
+// patterns. Empty patterns match anything.
+type CategoryRule struct {
+	Categoria   string
+	Title       *regexp.Regexp
+	URL         *regexp.Regexp
+	Publication *regexp.Regexp
+}
+
+func NewCategoryRule(categoria, title, url, publication string) (CategoryRule, error) {
+	categoria = strings.TrimSpace(categoria)
+	if categoria == "" || strings.ContainsAny(categoria, `/\`) {
+		return CategoryRule{}, fmt.Errorf("nome de categoria inválido: %q", categoria)
+	}
+	if title == "" && url == "" && publication == "" {
+		return CategoryRule{}, fmt.Errorf("categoria %s sem nenhum padrão", categoria)
+	}
+
+	rule := CategoryRule{Categoria: categoria}
+	for _, field := range []struct {
+		pattern string
+		target  **regexp.Regexp
+	}{
+		{title, &rule.Title},
+		{url, &rule.URL},
+		{publication, &rule.Publication},
+	} {
+		if field.pattern == "" {
+			continue
+		}
+		compiled, err := regexp.Compile(field.pattern)
+		if err != nil {
+			return CategoryRule{}, fmt.Errorf("categoria %s: padrão inválido %q: %w", categoria, field.pattern, err)
+		}
+		*field.target = compiled
+	}
+	return rule, nil
+}
+
+func (r CategoryRule) Matches(clipe ClipeMusical) bool {
+	return (r.Title == nil || r.Title.MatchString(clipe.Titulo)) &&
+		(r.URL == nil || r.URL.MatchString(clipe.URL)) &&
+		(r.Publication == nil || r.Publication.MatchString(clipe.GetPublicacao()))
+}
+
+// DefaultCategoryRules recognize the known series of the catalog; configured
+// rules are checked before them.
+var DefaultCategoryRules = []CategoryRule{
+	{Categoria: "congresso", Title: regexp.MustCompile(`(?i)congresso|convention|asamblea|assembleia`)},
+	{Categoria: "cante-de-coracao", Title: regexp.MustCompile(`(?i)cante de cora[cç][aã]o`)},
+	{Categoria: "jw-broadcasting", Title: regexp.MustCompile(`(?i)jw broadcasting|programa mensal`)},
+	{Categoria: "jw-broadcasting", URL: regexp.MustCompile(`(?i)jw-?broadcasting`)},
+	{Categoria: "canticos", Publication: regexp.MustCompile(`^sjj`)},
+}
+
+// Classify sets Categoria from the first matching rule, leaving it empty
+// when none matches.
+func (c *ClipeMusical) Classify(rules []CategoryRule) {
+	c.Categoria = ""
+	for _, rule := range rules {
+		if rule.Matches(*c) {
+			c.Categoria = rule.Categoria
+			return
+		}
+	}
+}
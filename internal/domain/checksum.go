@@ -0,0 +1,94 @@
+package domain
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+)
+
+const (
+	id3HeaderSize = 10
+	id3FooterFlag = 0x10
+)
+
+// ID3v2TagSize returns the total size of the ID3v2 tag opened by header,
+// footer included, or false when header does not start a valid tag.
+func ID3v2TagSize(header []byte) (int64, bool) {
+	if len(header) < id3HeaderSize || !bytes.HasPrefix(header, []byte("ID3")) {
+		return 0, false
+	}
+
+	var size int64
+	for _, b := range header[6:10] {
+		if b&0x80 != 0 {
+			return 0, false
+		}
+		size = size<<7 | int64(b)
+	}
+
+	total := id3HeaderSize + size
+	if header[5]&id3FooterFlag != 0 {
+		total += id3HeaderSize
+	}
+	return total, true
+}
+
+// PayloadHash is the SHA-256 of the audio written to it, leaving out the
+// leading ID3v2 tags. Retagging a file keeps it, so it identifies the same
+// recording across releases with different metadata.
+type PayloadHash struct {
+	hash    hash.Hash
+	header  []byte
+	skip    int64
+	payload bool
+}
+
+func NewPayloadHash() *PayloadHash {
+	return &PayloadHash{hash: sha256.New()}
+}
+
+func (h *PayloadHash) Write(p []byte) (int, error) {
+	n := len(p)
+
+	for len(p) > 0 && !h.payload {
+		if h.skip > 0 {
+			skipped := min(h.skip, int64(len(p)))
+			h.skip -= skipped
+			p = p[skipped:]
+			continue
+		}
+
+		missing := min(id3HeaderSize-len(h.header), len(p))
+		h.header = append(h.header, p[:missing]...)
+		p = p[missing:]
+		if len(h.header) < id3HeaderSize {
+			continue
+		}
+
+		if size, ok := ID3v2TagSize(h.header); ok {
+			h.skip = size - id3HeaderSize
+			h.header = h.header[:0]
+			continue
+		}
+		h.startPayload()
+	}
+
+	h.hash.Write(p)
+	return n, nil
+}
+
+// Sum returns the hex digest. Content too short for a tag header is hashed
+// as it is.
+func (h *PayloadHash) Sum() string {
+	if !h.payload && h.skip == 0 {
+		h.startPayload()
+	}
+	return hex.EncodeToString(h.hash.Sum(nil))
+}
+
+func (h *PayloadHash) startPayload() {
+	h.payload = true
+	h.hash.Write(h.header)
+	h.header = nil
+}
@@ -0,0 +1,68 @@
+package domain
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+)
+
+func id3Tag(body string, footer bool) []byte {
+	flags := byte(0)
+	if footer {
+		flags = id3FooterFlag
+	}
+	size := len(body)
+	tag := []byte{'I', 'D', '3', 4, 0, flags, byte(size >> 21 & 0x7f), byte(size >> 14 & 0x7f), byte(size >> 7 & 0x7f), byte(size & 0x7f)}
+	tag = append(tag, body...)
+	if footer {
+		tag = append(tag, "3DI\x04\x00\x10\x00\x00\x00\x00"...)
+	}
+	return tag
+}
+
+func TestPayloadHash(t *testing.T) {
+	audio := "\xff\xfb\x90\x00audio"
+	plain := hashOf(audio)
+
+	tests := []struct {
+		name    string
+		content []byte
+		want    string
+	}{
+		{"untagged", []byte(audio), plain},
+		{"tagged", append(id3Tag("TIT2 Alegria", false), audio...), plain},
+		{"other tag", append(id3Tag("TIT2 Alegria (2024) e mais", false), audio...), plain},
+		{"footer", append(id3Tag("TIT2", true), audio...), plain},
+		{"two tags", append(append(id3Tag("a", false), id3Tag("bb", false)...), audio...), plain},
+		{"invalid size", []byte("ID3\x04\x00\x00\x80\x00\x00\x00" + audio), hashOf("ID3\x04\x00\x00\x80\x00\x00\x00" + audio)},
+		{"shorter than header", []byte("ID3"), hashOf("ID3")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, chunk := range []int{1, 3, len(tt.content)} {
+				hash := NewPayloadHash()
+				for i := 0; i < len(tt.content); i += chunk {
+					hash.Write(tt.content[i:min(i+chunk, len(tt.content))])
+				}
+				if got := hash.Sum(); got != tt.want {
+					t.Errorf("chunks of %d: Sum() = %s, want %s", chunk, got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestID3v2TagSize(t *testing.T) {
+	if size, ok := ID3v2TagSize(id3Tag("abc", true)); !ok || size != 23 {
+		t.Errorf("ID3v2TagSize with footer = %d, %v; want 23, true", size, ok)
+	}
+	if _, ok := ID3v2TagSize([]byte("\xff\xfb\x90\x00audio0")); ok {
+		t.Error("ID3v2TagSize accepted an untagged header")
+	}
+}
+
+func hashOf(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
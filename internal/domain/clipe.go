@@ -2,11 +2,14 @@ package domain
 
 import (
 	"fmt"
+	"path"
+	"strings"
 	"time"
 )
 
 type ClipeMusical struct {
 	ID             string
+	DocID          string // jw.org document id, when the listing exposes it
 	Titulo         string
 	Descricao      string
 	URL            string
@@ -15,6 +18,28 @@ type ClipeMusical struct {
 	DataPublicacao time.Time
 	NomeArquivo    string
 	Ano            int
+	Album          string
+	Faixa          int
+	Imagens        []string
+	SHA256         string
+	Tagged         bool
+	Duracao        float64 // seconds
+	Bitrate        int     // kbps
+	Idioma         string  // jw.org language code, e.g. T or E
+	// Chave identifies the song across languages (publication and track),
+	// e.g. osg-12.
+	Chave string
+	// Publicacao is the jw.org publication code the clip belongs to.
+	Publicacao string
+	// Categoria is the series of the clip, e.g. congresso.
+	Categoria string
+	// Relacionados are the songbook numbers the clip page refers to.
+	Relacionados []int
+	// Diretorio replaces the year folder when a folder layout is used.
+	Diretorio string
+	// Pasta is an optional folder the year folders are nested in, such as
+	// the code of a language other than the default one.
+	Pasta string
 }
 
 func (c *ClipeMusical) IsValid() bool {
@@ -26,40 +51,94 @@ func (c *ClipeMusical) GetSanitizedFilename() string {
 		return c.NomeArquivo
 	}
 
-	filename := c.Titulo
-	sanitized := ""
-	for _, char := range filename {
-		switch {
-		case char >= 'a' && char <= 'z',
-			char >= 'A' && char <= 'Z',
-			char >= '0' && char <= '9':
-			sanitized += string(char)
-		case char == ' ':
-			sanitized += "_"
-		case char == 'ã', char == 'á', char == 'à', char == 'â':
-			sanitized += "a"
-		case char == 'é', char == 'ê':
-			sanitized += "e"
-		case char == 'í':
-			sanitized += "i"
-		case char == 'õ', char == 'ó', char == 'ô':
-			sanitized += "o"
-		case char == 'ú', char == 'û':
-			sanitized += "u"
-		case char == 'ç':
-			sanitized += "c"
-		default:
-			// Ignore any other special characters
-		}
-	}
+	return c.ApplyFilenameStyle(DefaultFilenameStyle)
+}
 
-	c.NomeArquivo = sanitized + ".mp3"
+// ApplyFilenameStyle recomputes NomeArquivo from the title using the given style.
+func (c *ClipeMusical) ApplyFilenameStyle(style FilenameStyle) string {
+	c.NomeArquivo = SanitizeFilename(c.Titulo, style) + c.extension()
 	return c.NomeArquivo
 }
 
+// extension follows the format of the download, which is MP3 unless the
+// publication prefers another one.
+func (c *ClipeMusical) extension() string {
+	if c.URLDownload != "" {
+		switch ext := strings.ToLower(path.Ext(c.URLDownload)); ext {
+		case ".m4a", ".aac":
+			return ext
+		}
+	}
+	return ".mp3"
+}
+
+// GetDirectoryPath returns the folder of the clip: its year, or its category
+// when the year is unknown, below Pasta.
 func (c *ClipeMusical) GetDirectoryPath() string {
+	dir := c.Diretorio
+	if dir == "" {
+		dir = "outros"
+		if c.Ano > 0 {
+			dir = fmt.Sprintf("%d", c.Ano)
+		} else if c.Categoria != "" {
+			dir = c.Categoria
+		}
+	}
+	if c.Pasta != "" {
+		return path.Join(c.Pasta, dir)
+	}
+	return dir
+}
+
+// ApplyFolderLayout sets Diretorio from a layout such as {category}/{year}.
+// Unknown years and categories become outros; an empty layout keeps the
+// default of GetDirectoryPath.
+func (c *ClipeMusical) ApplyFolderLayout(layout string) {
+	if layout == "" {
+		c.Diretorio = ""
+		return
+	}
+
+	year, category := "outros", "outros"
 	if c.Ano > 0 {
-		return fmt.Sprintf("%d", c.Ano)
+		year = fmt.Sprintf("%d", c.Ano)
+	}
+	if c.Categoria != "" {
+		category = c.Categoria
+	}
+	replacer := strings.NewReplacer("{year}", year, "{category}", category, "{language}", c.GetIdioma())
+	c.Diretorio = path.Clean(replacer.Replace(layout))
+}
+
+// GetIdioma returns the language of the clip; clips saved before languages
+// were tracked are Portuguese.
+func (c *ClipeMusical) GetIdioma() string {
+	if c.Idioma == "" {
+		return DefaultLanguage
+	}
+	return c.Idioma
+}
+
+// GetPublicacao returns the publication of the clip; clips saved before
+// publications were tracked are music videos.
+func (c *ClipeMusical) GetPublicacao() string {
+	if c.Publicacao == "" {
+		return DefaultPublication
+	}
+	return c.Publicacao
+}
+
+// GetRelativePath returns the slash separated location of the clip inside
+// the library, independent of the storage backend.
+func (c *ClipeMusical) GetRelativePath() string {
+	return path.Join(c.GetDirectoryPath(), c.GetSanitizedFilename())
+}
+
+// FormatDuration renders a duration in seconds as m:ss, or h:mm:ss.
+func FormatDuration(seconds float64) string {
+	total := int(seconds + 0.5)
+	if total >= 3600 {
+		return fmt.Sprintf("%d:%02d:%02d", total/3600, total%3600/60, total%60)
 	}
-	return "outros"
+	return fmt.Sprintf("%d:%02d", total/60, total%60)
 }
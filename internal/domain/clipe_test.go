@@ -0,0 +1,22 @@
+package domain
+
+import "testing"
+
+func TestApplyFilenameStyleFollowsFormat(t *testing.T) {
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{"", "Alegria.mp3"},
+		{"https://cdn.example/osg_T_01.mp3", "Alegria.mp3"},
+		{"https://cdn.example/sjjm_T_01.m4a", "Alegria.m4a"},
+		{"https://cdn.example/sjjm_T_01.AAC", "Alegria.aac"},
+	}
+
+	for _, tt := range tests {
+		clipe := ClipeMusical{Titulo: "Alegria", URLDownload: tt.url}
+		if got := clipe.ApplyFilenameStyle(FilenameStyleASCIISnake); got != tt.want {
+			t.Errorf("URLDownload %q: name = %q, want %q", tt.url, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,203 @@
+package domain
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+type FilenameStyle string
+
+const (
+	FilenameStyleASCIISnake  FilenameStyle = "ascii-snake"
+	FilenameStyleASCIIKebab  FilenameStyle = "ascii-kebab"
+	FilenameStyleUnicodeSafe FilenameStyle = "unicode-safe"
+
+	DefaultFilenameStyle = FilenameStyleASCIISnake
+
+	// Most filesystems limit a single path component to 255 bytes. We keep
+	// room for the extension and for temporary suffixes such as ".tmp".
+	maxFilenameBytes = 200
+	hashSuffixLength = 8
+)
+
+var windowsReservedNames = map[string]bool{
+	"CON": true, "PRN": true, "AUX": true, "NUL": true,
+	"COM1": true, "COM2": true, "COM3": true, "COM4": true, "COM5": true,
+	"COM6": true, "COM7": true, "COM8": true, "COM9": true,
+	"LPT1": true, "LPT2": true, "LPT3": true, "LPT4": true, "LPT5": true,
+	"LPT6": true, "LPT7": true, "LPT8": true, "LPT9": true,
+}
+
+// Letters that have no canonical decomposition but a well known ASCII form.
+var transliterations = map[rune]string{
+	'ß': "ss", 'ẞ': "SS",
+	'æ': "ae", 'Æ': "AE",
+	'œ': "oe", 'Œ': "OE",
+	'ø': "o", 'Ø': "O",
+	'đ': "d", 'Đ': "D",
+	'ð': "d", 'Ð': "D",
+	'ł': "l", 'Ł': "L",
+	'þ': "th", 'Þ': "TH",
+	'ı': "i", 'ħ': "h", 'Ħ': "H",
+
+	// Greek
+	'α': "a", 'β': "v", 'γ': "g", 'δ': "d", 'ε': "e", 'ζ': "z", 'η': "i",
+	'θ': "th", 'ι': "i", 'κ': "k", 'λ': "l", 'μ': "m", 'ν': "n", 'ξ': "x",
+	'ο': "o", 'π': "p", 'ρ': "r", 'σ': "s", 'ς': "s", 'τ': "t", 'υ': "y",
+	'φ': "f", 'χ': "ch", 'ψ': "ps", 'ω': "o",
+	'Α': "A", 'Β': "V", 'Γ': "G", 'Δ': "D", 'Ε': "E", 'Ζ': "Z", 'Η': "I",
+	'Θ': "Th", 'Ι': "I", 'Κ': "K", 'Λ': "L", 'Μ': "M", 'Ν': "N", 'Ξ': "X",
+	'Ο': "O", 'Π': "P", 'Ρ': "R", 'Σ': "S", 'Τ': "T", 'Υ': "Y",
+	'Φ': "F", 'Χ': "Ch", 'Ψ': "Ps", 'Ω': "O",
+
+	// Cyrillic
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ж': "zh",
+	'з': "z", 'и': "i", 'к': "k", 'л': "l", 'м': "m", 'н': "n",
+	'о': "o", 'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u", 'ф': "f",
+	'х': "kh", 'ц': "ts", 'ч': "ch", 'ш': "sh", 'щ': "shch", 'ъ': "",
+	'ы': "y", 'ь': "", 'э': "e", 'ю': "yu", 'я': "ya", 'є': "ye", 'і': "i",
+	'ґ': "g",
+	'А': "A", 'Б': "B", 'В': "V", 'Г': "G", 'Д': "D", 'Е': "E", 'Ж': "Zh",
+	'З': "Z", 'И': "I", 'К': "K", 'Л': "L", 'М': "M", 'Н': "N",
+	'О': "O", 'П': "P", 'Р': "R", 'С': "S", 'Т': "T", 'У': "U", 'Ф': "F",
+	'Х': "Kh", 'Ц': "Ts", 'Ч': "Ch", 'Ш': "Sh", 'Щ': "Shch", 'Ъ': "",
+	'Ы': "Y", 'Ь': "", 'Э': "E", 'Ю': "Yu", 'Я': "Ya", 'Є': "Ye", 'І': "I",
+	'Ґ': "G",
+}
+
+// Characters removed without leaving a word separator behind.
+var droppedPunctuation = map[rune]bool{
+	'\'': true, '’': true, '‘': true, 'ʼ': true, '`': true, '´': true,
+}
+
+func ParseFilenameStyle(value string) (FilenameStyle, error) {
+	switch style := FilenameStyle(strings.ToLower(strings.TrimSpace(value))); style {
+	case "":
+		return DefaultFilenameStyle, nil
+	case FilenameStyleASCIISnake, FilenameStyleASCIIKebab, FilenameStyleUnicodeSafe:
+		return style, nil
+	default:
+		return "", fmt.Errorf("estilo de nome de arquivo inválido: %s", value)
+	}
+}
+
+// SanitizeFilename turns a title into a portable file name (without
+// extension) following the given style. It never returns an empty string.
+func SanitizeFilename(title string, style FilenameStyle) string {
+	if style == "" {
+		style = DefaultFilenameStyle
+	}
+
+	separator := "_"
+	if style == FilenameStyleASCIIKebab {
+		separator = "-"
+	}
+
+	var b strings.Builder
+	pendingSeparator := false
+	writeToken := func(token string) {
+		if token == "" {
+			return
+		}
+		if pendingSeparator && b.Len() > 0 {
+			b.WriteString(separator)
+		}
+		pendingSeparator = false
+		b.WriteString(token)
+	}
+
+	for _, char := range norm.NFD.String(title) {
+		switch {
+		case unicode.Is(unicode.Mn, char):
+			if style == FilenameStyleUnicodeSafe {
+				b.WriteRune(char)
+			}
+		case droppedPunctuation[char]:
+		case char < utf8.RuneSelf && (unicode.IsLetter(char) || unicode.IsDigit(char)):
+			writeToken(string(char))
+		case unicode.IsDigit(char):
+			if style == FilenameStyleUnicodeSafe {
+				writeToken(string(char))
+			} else {
+				writeToken(string(rune('0' + digitValue(char))))
+			}
+		case unicode.IsLetter(char):
+			if style == FilenameStyleUnicodeSafe {
+				writeToken(string(char))
+			} else if ascii, ok := transliterations[char]; ok {
+				writeToken(ascii)
+			}
+		default:
+			// Whitespace, punctuation, symbols and reserved characters
+			// such as <>:"/\|?* all become word separators.
+			pendingSeparator = true
+		}
+	}
+
+	sanitized := b.String()
+	if style == FilenameStyleUnicodeSafe {
+		sanitized = norm.NFC.String(sanitized)
+	}
+	if style == FilenameStyleASCIIKebab {
+		sanitized = strings.ToLower(sanitized)
+	}
+
+	if sanitized == "" {
+		return "clipe_" + stableHash(title)
+	}
+
+	if windowsReservedNames[strings.ToUpper(sanitized)] {
+		sanitized += separator
+	}
+
+	return truncateFilename(sanitized, title, separator)
+}
+
+func truncateFilename(name, original, separator string) string {
+	if len(name) <= maxFilenameBytes {
+		return name
+	}
+
+	limit := maxFilenameBytes - len(separator) - hashSuffixLength
+	for limit > 0 && !utf8.RuneStart(name[limit]) {
+		limit--
+	}
+
+	// Never cut a base letter away from its combining marks.
+	truncated := name[:limit]
+	for {
+		last, size := utf8.DecodeLastRuneInString(truncated)
+		if size == 0 || !unicode.Is(unicode.Mn, last) {
+			break
+		}
+		truncated = truncated[:len(truncated)-size]
+	}
+	truncated = strings.TrimRight(truncated, separator)
+
+	return truncated + separator + stableHash(original)
+}
+
+func stableHash(value string) string {
+	sum := sha1.Sum([]byte(value))
+	return hex.EncodeToString(sum[:])[:hashSuffixLength]
+}
+
+func digitValue(char rune) int {
+	for _, r := range unicode.Nd.R16 {
+		if rune(r.Lo) <= char && char <= rune(r.Hi) {
+			return int(char-rune(r.Lo)) % 10
+		}
+	}
+	for _, r := range unicode.Nd.R32 {
+		if rune(r.Lo) <= char && char <= rune(r.Hi) {
+			return int(char-rune(r.Lo)) % 10
+		}
+	}
+	return 0
+}
@@ -0,0 +1,87 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestSanitizeFilename(t *testing.T) {
+	tests := []struct {
+		name  string
+		title string
+		style FilenameStyle
+		want  string
+	}{
+		{"accents snake", "Canção de Louvor", FilenameStyleASCIISnake, "Cancao_de_Louvor"},
+		{"accents kebab", "Canção de Louvor", FilenameStyleASCIIKebab, "cancao-de-louvor"},
+		{"accents unicode", "Canção de Louvor", FilenameStyleUnicodeSafe, "Canção_de_Louvor"},
+		{"cedilla and tilde", "Ação, Coração e Irmãos", FilenameStyleASCIISnake, "Acao_Coracao_e_Irmaos"},
+		{"apostrophe dropped", "Jehovah's Way", FilenameStyleASCIIKebab, "jehovahs-way"},
+		{"emoji snake", "Alegria 🎵 Sempre 🙂", FilenameStyleASCIISnake, "Alegria_Sempre"},
+		{"emoji unicode", "🎵 Alegria", FilenameStyleUnicodeSafe, "Alegria"},
+		{"only emoji", "🎵🎶", FilenameStyleASCIISnake, "clipe_" + stableHash("🎵🎶")},
+		{"reserved characters", `a<b>c:d"e/f\g|h?i*j`, FilenameStyleASCIISnake, "a_b_c_d_e_f_g_h_i_j"},
+		{"windows reserved snake", "CON", FilenameStyleASCIISnake, "CON_"},
+		{"windows reserved kebab", "Lpt1", FilenameStyleASCIIKebab, "lpt1-"},
+		{"windows reserved unicode", "aux", FilenameStyleUnicodeSafe, "aux_"},
+		{"trailing dots and spaces", "Fim...   ", FilenameStyleASCIISnake, "Fim"},
+		{"leading dots and spaces", " .. Início", FilenameStyleUnicodeSafe, "Início"},
+		{"transliteration", "Straße Łódź", FilenameStyleASCIIKebab, "strasse-lodz"},
+		{"cyrillic", "Песня", FilenameStyleASCIISnake, "Pesnya"},
+		{"empty style", "Canção", "", "Cancao"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SanitizeFilename(tt.title, tt.style); got != tt.want {
+				t.Errorf("SanitizeFilename(%q, %q) = %q, want %q", tt.title, tt.style, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSanitizeFilenameTruncates(t *testing.T) {
+	tests := []struct {
+		name      string
+		title     string
+		style     FilenameStyle
+		separator string
+	}{
+		{"snake", strings.Repeat("palavra ", 40), FilenameStyleASCIISnake, "_"},
+		{"kebab", strings.Repeat("Palavra ", 40), FilenameStyleASCIIKebab, "-"},
+		{"unicode", strings.Repeat("canção ", 40), FilenameStyleUnicodeSafe, "_"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := SanitizeFilename(tt.title, tt.style)
+
+			if len(got) > maxFilenameBytes {
+				t.Errorf("len = %d bytes, want at most %d", len(got), maxFilenameBytes)
+			}
+			if !utf8.ValidString(got) {
+				t.Errorf("truncated name is not valid UTF-8: %q", got)
+			}
+			if suffix := tt.separator + stableHash(tt.title); !strings.HasSuffix(got, suffix) {
+				t.Errorf("%q does not end with hash suffix %q", got, suffix)
+			}
+			if strings.Contains(got, tt.separator+tt.separator) {
+				t.Errorf("%q has a doubled separator", got)
+			}
+		})
+	}
+
+	// Titles that differ only after the cap keep distinct names.
+	a := SanitizeFilename(strings.Repeat("a", 250)+"1", FilenameStyleASCIISnake)
+	b := SanitizeFilename(strings.Repeat("a", 250)+"2", FilenameStyleASCIISnake)
+	if a == b {
+		t.Errorf("long titles collided: %q", a)
+	}
+
+	// Names at the cap are left alone.
+	exact := strings.Repeat("a", maxFilenameBytes)
+	if got := SanitizeFilename(exact, FilenameStyleASCIISnake); got != exact {
+		t.Errorf("name of %d bytes was changed to %q", maxFilenameBytes, got)
+	}
+}
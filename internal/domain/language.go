@@ -0,0 +1,39 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DefaultLanguage is the jw.org code of Portuguese, the original catalog.
+const DefaultLanguage = "T"
+
+// ParseLanguages normalizes a list of jw.org language codes, accepting
+// comma separated values.
+func ParseLanguages(values []string) ([]string, error) {
+	var languages []string
+	seen := make(map[string]bool)
+
+	for _, value := range values {
+		for _, code := range strings.Split(value, ",") {
+			code = strings.ToUpper(strings.TrimSpace(code))
+			if code == "" {
+				continue
+			}
+			for _, r := range code {
+				if (r < 'A' || r > 'Z') && (r < '0' || r > '9') && r != '-' {
+					return nil, fmt.Errorf("código de idioma inválido: %s", code)
+				}
+			}
+			if !seen[code] {
+				seen[code] = true
+				languages = append(languages, code)
+			}
+		}
+	}
+
+	if len(languages) == 0 {
+		return []string{DefaultLanguage}, nil
+	}
+	return languages, nil
+}
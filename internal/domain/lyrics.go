@@ -0,0 +1,28 @@
+package domain
+
+import (
+	"regexp"
+	"strings"
+)
+
+// LRC timing markers such as [01:23.45] at the start of a line.
+var lyricsTimingPattern = regexp.MustCompile(`^(\[\d{1,2}:\d{2}(?:[.:]\d{2,3})?\])+\s*`)
+
+// HasLyricsTimings reports whether the lyrics carry LRC timing markers.
+func HasLyricsTimings(lyrics string) bool {
+	for _, line := range strings.Split(lyrics, "\n") {
+		if lyricsTimingPattern.MatchString(strings.TrimSpace(line)) {
+			return true
+		}
+	}
+	return false
+}
+
+// StripLyricsTimings returns the plain text of the lyrics.
+func StripLyricsTimings(lyrics string) string {
+	lines := strings.Split(lyrics, "\n")
+	for i, line := range lines {
+		lines[i] = lyricsTimingPattern.ReplaceAllString(strings.TrimSpace(line), "")
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
@@ -0,0 +1,178 @@
+package domain
+
+import (
+	"path"
+	"sort"
+	"time"
+)
+
+const ManifestVersion = 1
+
+type ManifestEntry struct {
+	Path         string   `json:"path"`
+	ID           string   `json:"id,omitempty"`
+	DocID        string   `json:"docid,omitempty"`
+	Titulo       string   `json:"titulo"`
+	Descricao    string   `json:"descricao,omitempty"`
+	URL          string   `json:"url,omitempty"`
+	URLDownload  string   `json:"url_download,omitempty"`
+	Ano          int      `json:"ano,omitempty"`
+	Album        string   `json:"album,omitempty"`
+	Faixa        int      `json:"faixa,omitempty"`
+	Imagens      []string `json:"imagens,omitempty"`
+	Size         int64    `json:"size"`
+	SHA256       string   `json:"sha256,omitempty"`
+	Tagged       bool     `json:"tagged,omitempty"`
+	Duracao      float64  `json:"duracao,omitempty"`
+	Bitrate      int      `json:"bitrate,omitempty"`
+	Idioma       string   `json:"idioma,omitempty"`
+	Chave        string   `json:"chave,omitempty"`
+	Publicacao   string   `json:"publicacao,omitempty"`
+	Categoria    string   `json:"categoria,omitempty"`
+	Relacionados []int    `json:"relacionados,omitempty"`
+	// DataPublicacao is when jw.org published the file, when known.
+	DataPublicacao *time.Time `json:"data_publicacao,omitempty"`
+	DownloadedAt   time.Time  `json:"downloaded_at"`
+}
+
+// ManifestAlias records that a file name is served by another file with
+// identical content, so it is not downloaded again under that name.
+type ManifestAlias struct {
+	Filename  string    `json:"filename"`
+	Titulo    string    `json:"titulo,omitempty"`
+	Canonical string    `json:"canonical"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type Manifest struct {
+	Version int                      `json:"version"`
+	Entries map[string]ManifestEntry `json:"entries"`
+	Aliases map[string]ManifestAlias `json:"aliases,omitempty"`
+}
+
+func NewManifest() *Manifest {
+	return &Manifest{
+		Version: ManifestVersion,
+		Entries: make(map[string]ManifestEntry),
+		Aliases: make(map[string]ManifestAlias),
+	}
+}
+
+func (m *Manifest) Put(entry ManifestEntry) {
+	m.ensureMaps()
+	m.Entries[entry.Path] = entry
+}
+
+func (m *Manifest) Get(entryPath string) (ManifestEntry, bool) {
+	entry, exists := m.Entries[entryPath]
+	return entry, exists
+}
+
+func (m *Manifest) Remove(entryPath string) {
+	delete(m.Entries, entryPath)
+}
+
+// AddAlias records relPath as a duplicate of canonical.
+func (m *Manifest) AddAlias(relPath, titulo, canonical string) {
+	m.ensureMaps()
+	m.Aliases[relPath] = ManifestAlias{
+		Filename:  relPath,
+		Titulo:    titulo,
+		Canonical: canonical,
+		CreatedAt: time.Now(),
+	}
+}
+
+// ResolveAlias returns the canonical file of a duplicate, looked up by its
+// path in the library.
+func (m *Manifest) ResolveAlias(relPath string) (string, bool) {
+	alias, exists := m.Aliases[relPath]
+	if !exists {
+		return "", false
+	}
+	return alias.Canonical, true
+}
+
+func (m *Manifest) SortedEntries() []ManifestEntry {
+	entries := make([]ManifestEntry, 0, len(m.Entries))
+	for _, entry := range m.Entries {
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Path < entries[j].Path
+	})
+	return entries
+}
+
+func (m *Manifest) Clone() *Manifest {
+	clone := NewManifest()
+	clone.Version = m.Version
+	for key, entry := range m.Entries {
+		clone.Entries[key] = entry
+	}
+	for key, alias := range m.Aliases {
+		clone.Aliases[key] = alias
+	}
+	return clone
+}
+
+func (m *Manifest) ensureMaps() {
+	if m.Entries == nil {
+		m.Entries = make(map[string]ManifestEntry)
+	}
+	if m.Aliases == nil {
+		m.Aliases = make(map[string]ManifestAlias)
+	}
+}
+
+// GetIdioma returns the language of the entry, see ClipeMusical.GetIdioma.
+func (e ManifestEntry) GetIdioma() string {
+	if e.Idioma == "" {
+		return DefaultLanguage
+	}
+	return e.Idioma
+}
+
+// GetPublicacao returns the publication of the entry, see
+// ClipeMusical.GetPublicacao.
+func (e ManifestEntry) GetPublicacao() string {
+	if e.Publicacao == "" {
+		return DefaultPublication
+	}
+	return e.Publicacao
+}
+
+// ToClipe rebuilds the clip an entry was created from.
+func (e ManifestEntry) ToClipe() ClipeMusical {
+	var publicado time.Time
+	if e.DataPublicacao != nil {
+		publicado = *e.DataPublicacao
+	}
+
+	return ClipeMusical{
+		ID:             e.ID,
+		DocID:          e.DocID,
+		Titulo:         e.Titulo,
+		URL:            e.URL,
+		URLDownload:    e.URLDownload,
+		TamanhoArquivo: e.Size,
+		NomeArquivo:    path.Base(e.Path),
+		Ano:            e.Ano,
+		Album:          e.Album,
+		Faixa:          e.Faixa,
+		Imagens:        e.Imagens,
+		SHA256:         e.SHA256,
+		Tagged:         e.Tagged,
+		Duracao:        e.Duracao,
+		Bitrate:        e.Bitrate,
+		Idioma:         e.Idioma,
+		Chave:          e.Chave,
+		Publicacao:     e.Publicacao,
+		DataPublicacao: publicado,
+		Categoria:      e.Categoria,
+		Descricao:      e.Descricao,
+		Relacionados:   e.Relacionados,
+		// The entry keeps the folder it was saved in, whatever the layout.
+		Diretorio: path.Dir(e.Path),
+	}
+}
@@ -0,0 +1,29 @@
+package domain
+
+import "testing"
+
+func TestManifestResolveAlias(t *testing.T) {
+	m := NewManifest()
+	m.AddAlias("E/2024/Alegria.mp3", "Alegria", "E/2024/Alegria_sempre.mp3")
+	// Keyed by file name only, the same name in every folder would match.
+	m.Aliases["Antigo.mp3"] = ManifestAlias{Filename: "Antigo.mp3", Canonical: "2023/Novo.mp3"}
+
+	tests := []struct {
+		relPath   string
+		canonical string
+		found     bool
+	}{
+		{"E/2024/Alegria.mp3", "E/2024/Alegria_sempre.mp3", true},
+		{"T/2024/Alegria.mp3", "", false},
+		{"Alegria.mp3", "", false},
+		{"2023/Antigo.mp3", "", false},
+		{"E/2023/Antigo.mp3", "", false},
+	}
+
+	for _, tt := range tests {
+		canonical, found := m.ResolveAlias(tt.relPath)
+		if canonical != tt.canonical || found != tt.found {
+			t.Errorf("ResolveAlias(%q) = %q, %v; want %q, %v", tt.relPath, canonical, found, tt.canonical, tt.found)
+		}
+	}
+}
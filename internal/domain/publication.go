@@ -0,0 +1,51 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DefaultPublication is the jw.org code of the music video collection, the
+// original catalog.
+const DefaultPublication = "osg"
+
+// AudioFormats are the pub-media file formats that can be downloaded, as
+// named by the API.
+var AudioFormats = []string{"MP3", "AAC"}
+
+// ParsePublicationCode normalizes a jw.org publication code such as osg or
+// sjjm.
+func ParsePublicationCode(value string) (string, error) {
+	code := strings.ToLower(strings.TrimSpace(value))
+	if code == "" {
+		return "", fmt.Errorf("código de publicação vazio")
+	}
+	for _, r := range code {
+		if (r < 'a' || r > 'z') && (r < '0' || r > '9') && r != '-' {
+			return "", fmt.Errorf("código de publicação inválido: %s", value)
+		}
+	}
+	return code, nil
+}
+
+// ParseAudioFormats normalizes a format preference list, most preferred
+// first. An empty list means MP3 only.
+func ParseAudioFormats(values []string) ([]string, error) {
+	var formats []string
+	for _, value := range values {
+		format := strings.ToUpper(strings.TrimSpace(value))
+		known := false
+		for _, supported := range AudioFormats {
+			known = known || format == supported
+		}
+		if !known {
+			return nil, fmt.Errorf("formato de áudio inválido: %s (use MP3 ou AAC)", value)
+		}
+		formats = append(formats, format)
+	}
+
+	if len(formats) == 0 {
+		return []string{"MP3"}, nil
+	}
+	return formats, nil
+}
@@ -1,21 +1,147 @@
 package domain
 
+import (
+	"errors"
+	"io"
+	"time"
+)
+
 type ClipeRepository interface {
 	FindAll() ([]ClipeMusical, error)
 	Save(clipe ClipeMusical) error
-	Exists(filename string) bool
+	// Exists takes the path of a clip inside the library, as given by
+	// GetRelativePath.
+	Exists(relPath string) bool
 	GetOutputDirectory() string
 	CreateDirectoryStructure(clipe ClipeMusical) error
+	OpenWriter(clipe ClipeMusical) (ClipeWriter, error)
+}
+
+// ClipeWriter receives the content of a clip while it is downloaded. Nothing
+// becomes visible in the repository until Commit succeeds.
+type ClipeWriter interface {
+	io.Writer
+	Location() string
+	Commit() error
+	Abort() error
+}
+
+type LibraryFile struct {
+	Path    string
+	Size    int64
+	ModTime time.Time
+}
+
+// LibraryAction is one change applied to the library, kept in an action log.
+type LibraryAction struct {
+	Time   time.Time `json:"time"`
+	Action string    `json:"action"`
+	Path   string    `json:"path"`
+	Target string    `json:"target,omitempty"`
+	Reason string    `json:"reason,omitempty"`
+	DryRun bool      `json:"dry_run,omitempty"`
+}
+
+type LibraryStorage interface {
+	ClipeRepository
+	ListFiles() ([]LibraryFile, error)
+	HashFile(path string) (string, error)
+	OpenFile(path string) (io.ReadCloser, error)
+	FileSize(path string) (int64, error)
+	CreateFile(path string) (ClipeWriter, error)
+	FileExists(path string) bool
+	FindSidecars(path string) ([]string, error)
+	LinkDuplicate(canonical, duplicate string) error
+	RemoveFile(path string) error
+	ArchiveFile(path string) (string, error)
+	AppendActionLog(action LibraryAction) error
+	ReadManifest() (*Manifest, error)
+	UpdateManifest(update func(manifest *Manifest)) error
 }
 
 type WebScraper interface {
-	ScrapClipesList(url string) ([]ClipeMusical, error)
+	// ScrapClipesList returns the catalog of a language. When only part of
+	// it could be read, the clips found come with ErrIncompleteCatalog.
+	ScrapClipesList(idioma string) ([]ClipeMusical, error)
 	ScrapClipeDetails(clipe ClipeMusical) (ClipeMusical, error)
 }
 
+// MatchReporter is implemented by scrapers that can tell which upstream
+// files were not matched to any listing item.
+type MatchReporter interface {
+	UnmatchedRemoteFiles() []RemoteFile
+}
+
+// RemoteFile is a file as currently published upstream.
+type RemoteFile struct {
+	Titulo      string
+	URLDownload string
+	Size        int64
+	Checksum    string
+	ModifiedAt  time.Time
+	Idioma      string
+}
+
+type CatalogSource interface {
+	FetchRemoteFiles() ([]RemoteFile, error)
+}
+
+// AudioTagger writes metadata while copying audio from src to dst.
+type AudioTagger interface {
+	Supports(clipe ClipeMusical) bool
+	Tag(dst io.Writer, src io.Reader, clipe ClipeMusical) error
+}
+
+var (
+	ErrNotAudio       = errors.New("conteúdo não é áudio")
+	ErrTruncatedAudio = errors.New("áudio truncado")
+	// ErrIncompleteCatalog marks a listing missing part of the catalog, which
+	// must not be mirrored.
+	ErrIncompleteCatalog = errors.New("catálogo incompleto")
+)
+
+// AudioInfo describes an inspected audio stream. Duration is in seconds and
+// Bitrate is the average in kbps.
+type AudioInfo struct {
+	Duration   float64
+	Bitrate    int
+	SampleRate int
+	Frames     int
+	VBR        bool
+}
+
+// AudioInspection receives a whole file through Write and validates it on
+// Finish, returning ErrNotAudio or ErrTruncatedAudio for broken payloads.
+type AudioInspection interface {
+	io.Writer
+	Finish() (AudioInfo, error)
+}
+
+type AudioInspector interface {
+	Supports(filename string) bool
+	NewInspection() AudioInspection
+}
+
+type CoverImage struct {
+	URL      string
+	MIMEType string
+	Data     []byte
+}
+
+type CoverSource interface {
+	FetchCover(clipe ClipeMusical) (*CoverImage, error)
+}
+
+type LyricsSource interface {
+	FetchLyrics(clipe ClipeMusical) (string, error)
+}
+
 type DownloadService interface {
 	Download(clipe ClipeMusical, destPath string) error
 	DownloadBatch(clipes []ClipeMusical, destPath string) error
+	// ReplaceBatch downloads the clips again even if their files exist. The
+	// old files are only replaced once the new ones are complete.
+	ReplaceBatch(clipes []ClipeMusical, destPath string) error
 	SetProgressCallback(callback func(current, total int64, filename string))
 }
 
@@ -0,0 +1,98 @@
+package domain
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// YearSource decides where the year folder of a clip comes from. Title is the
+// default and only reads the title, as libraries were always laid out; the
+// other sources fall back to each other when they have no year.
+type YearSource string
+
+const (
+	YearSourcePublication YearSource = "publication"
+	YearSourceTitle       YearSource = "title"
+	YearSourceConvention  YearSource = "convention"
+)
+
+func ParseYearSource(value string) (YearSource, error) {
+	switch source := YearSource(strings.ToLower(strings.TrimSpace(value))); source {
+	case "":
+		return YearSourceTitle, nil
+	case YearSourcePublication, YearSourceTitle, YearSourceConvention:
+		return source, nil
+	default:
+		return "", fmt.Errorf("origem do ano inválida: %s (use publication, title ou convention)", value)
+	}
+}
+
+var (
+	titleYearPattern = regexp.MustCompile(`\b(19[5-9]\d|20\d{2})\b`)
+	// "Congresso de 2024", "Convention 2023", "2022 Convention".
+	conventionYearPatterns = []*regexp.Regexp{
+		regexp.MustCompile(`(?i)(?:congresso|convention|asamblea|assembleia|assembly)\D{0,30}\b(19[5-9]\d|20\d{2})\b`),
+		regexp.MustCompile(`(?i)\b(19[5-9]\d|20\d{2})\b\D{0,30}(?:congresso|convention|asamblea|assembleia|assembly)`),
+	}
+)
+
+// YearFromTitle returns the first year mentioned in a title, or 0.
+func YearFromTitle(title string) int {
+	if matches := titleYearPattern.FindStringSubmatch(title); matches != nil {
+		year, _ := strconv.Atoi(matches[1])
+		return year
+	}
+	return 0
+}
+
+// ConventionYear returns the year of the convention a title refers to, or 0.
+func ConventionYear(title string) int {
+	for _, pattern := range conventionYearPatterns {
+		if matches := pattern.FindStringSubmatch(title); matches != nil {
+			year, _ := strconv.Atoi(matches[1])
+			return year
+		}
+	}
+	return 0
+}
+
+// ResolveYear sets Ano from the preferred source. The title source leaves
+// clips without a year in the title without one; the others fall back to
+// each other and keep Ano when none has a year.
+func (c *ClipeMusical) ResolveYear(source YearSource) {
+	publication := func() int {
+		if c.DataPublicacao.IsZero() {
+			return 0
+		}
+		return c.DataPublicacao.Year()
+	}
+	title := func() int { return YearFromTitle(c.Titulo) }
+	convention := func() int { return ConventionYear(c.Titulo) }
+
+	order := []func() int{publication, title}
+	switch source {
+	case YearSourceTitle:
+		c.Ano = title()
+		return
+	case YearSourceConvention:
+		order = []func() int{convention, title, publication}
+	}
+
+	for _, year := range order {
+		if ano := year(); ano > 0 {
+			c.Ano = ano
+			return
+		}
+	}
+}
+
+// OptionalTime returns nil for the zero time, for fields omitted when unknown.
+func OptionalTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
@@ -0,0 +1,178 @@
+package audio
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/sant0x00/downloader-music/internal/domain"
+)
+
+const (
+	id3HeaderSize   = 10
+	encodingUTF8    = 0x03
+	pictureFront    = 0x03
+	defaultLanguage = "por"
+)
+
+// Tag holds the ID3v2.4 frames written to a file. Empty fields are omitted.
+type Tag struct {
+	Title    string
+	Artist   string
+	Album    string
+	Year     string
+	Track    string
+	Genre    string
+	Language string
+	Comment  string
+	URL      string
+	Lyrics   string
+
+	// Picture is embedded as the front cover when present.
+	Picture     []byte
+	PictureMIME string
+}
+
+// Encode renders the tag as a complete ID3v2.4 block.
+func (t Tag) Encode() []byte {
+	var frames bytes.Buffer
+
+	writeTextFrame(&frames, "TIT2", t.Title)
+	writeTextFrame(&frames, "TPE1", t.Artist)
+	writeTextFrame(&frames, "TALB", t.Album)
+	writeTextFrame(&frames, "TDRC", t.Year)
+	writeTextFrame(&frames, "TRCK", t.Track)
+	writeTextFrame(&frames, "TCON", t.Genre)
+	writeTextFrame(&frames, "TLAN", t.Language)
+
+	if t.Comment != "" {
+		var body bytes.Buffer
+		body.WriteByte(encodingUTF8)
+		body.WriteString(frameLanguage(t.Language))
+		body.WriteByte(0) // empty description
+		body.WriteString(t.Comment)
+		writeFrame(&frames, "COMM", body.Bytes())
+	}
+
+	if t.Lyrics != "" {
+		var body bytes.Buffer
+		body.WriteByte(encodingUTF8)
+		body.WriteString(frameLanguage(t.Language))
+		body.WriteByte(0) // empty description
+		body.WriteString(t.Lyrics)
+		writeFrame(&frames, "USLT", body.Bytes())
+	}
+
+	if t.URL != "" {
+		var body bytes.Buffer
+		body.WriteByte(encodingUTF8)
+		body.WriteString("Fonte")
+		body.WriteByte(0)
+		body.WriteString(t.URL) // URLs are always ISO-8859-1
+		writeFrame(&frames, "WXXX", body.Bytes())
+	}
+
+	if len(t.Picture) > 0 {
+		var body bytes.Buffer
+		body.WriteByte(encodingUTF8)
+		body.WriteString(t.PictureMIME) // MIME type is always ISO-8859-1
+		body.WriteByte(0)
+		body.WriteByte(pictureFront)
+		body.WriteByte(0) // empty description
+		body.Write(t.Picture)
+		writeFrame(&frames, "APIC", body.Bytes())
+	}
+
+	var tag bytes.Buffer
+	tag.WriteString("ID3")
+	tag.Write([]byte{0x04, 0x00, 0x00})
+	tag.Write(synchsafe(uint32(frames.Len())))
+	tag.Write(frames.Bytes())
+	return tag.Bytes()
+}
+
+// WriteTagged writes the tag followed by the audio read from src, dropping
+// any ID3v2 tag src already starts with.
+func WriteTagged(dst io.Writer, src io.Reader, tag Tag) error {
+	if _, err := dst.Write(tag.Encode()); err != nil {
+		return err
+	}
+
+	audio, err := SkipID3v2(src)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(dst, audio)
+	return err
+}
+
+// SkipID3v2 returns a reader positioned right after the leading ID3v2 tags
+// of src, if there are any.
+func SkipID3v2(src io.Reader) (io.Reader, error) {
+	reader := bufio.NewReader(src)
+
+	for {
+		header, err := reader.Peek(id3HeaderSize)
+		if err != nil || !bytes.HasPrefix(header, []byte("ID3")) {
+			// Short or untagged files are passed through untouched.
+			return reader, nil
+		}
+
+		total, ok := domain.ID3v2TagSize(header)
+		if !ok {
+			return reader, nil
+		}
+
+		if _, err := io.CopyN(io.Discard, reader, total); err != nil {
+			return nil, fmt.Errorf("tag ID3 truncada: %w", err)
+		}
+	}
+}
+
+// ReadID3v2Size returns the total size of the leading ID3v2 tag in data, or
+// zero when there is none.
+func ReadID3v2Size(data []byte) int {
+	total, ok := domain.ID3v2TagSize(data)
+	if !ok {
+		return 0
+	}
+	return int(total)
+}
+
+func writeTextFrame(frames *bytes.Buffer, id, value string) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return
+	}
+
+	body := make([]byte, 0, len(value)+1)
+	body = append(body, encodingUTF8)
+	body = append(body, value...)
+	writeFrame(frames, id, body)
+}
+
+func writeFrame(frames *bytes.Buffer, id string, body []byte) {
+	frames.WriteString(id)
+	frames.Write(synchsafe(uint32(len(body))))
+	frames.Write([]byte{0x00, 0x00})
+	frames.Write(body)
+}
+
+func frameLanguage(language string) string {
+	if len(language) != 3 {
+		return defaultLanguage
+	}
+	return strings.ToLower(language)
+}
+
+func synchsafe(value uint32) []byte {
+	return []byte{
+		byte(value>>21) & 0x7f,
+		byte(value>>14) & 0x7f,
+		byte(value>>7) & 0x7f,
+		byte(value) & 0x7f,
+	}
+}
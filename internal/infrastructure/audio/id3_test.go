@@ -0,0 +1,116 @@
+package audio
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+// withFooter turns an encoded tag into one flagged with a footer.
+func withFooter(tag []byte) []byte {
+	tagged := append([]byte(nil), tag...)
+	tagged[5] |= 0x10
+	footer := append([]byte("3DI"), tagged[3:10]...)
+	return append(tagged, footer...)
+}
+
+func TestTagEncode(t *testing.T) {
+	tag := Tag{
+		Title:       "Canção de Louvor",
+		Artist:      "JW.org",
+		Language:    "POR",
+		Comment:     "https://www.jw.org/",
+		URL:         "https://www.jw.org/",
+		Lyrics:      "Linha 1\nLinha 2",
+		Picture:     []byte{0xFF, 0xD8, 0xFF},
+		PictureMIME: "image/jpeg",
+	}
+	encoded := tag.Encode()
+
+	if !bytes.HasPrefix(encoded, []byte("ID3\x04\x00\x00")) {
+		t.Fatalf("header = % x, want an ID3v2.4 header", encoded[:6])
+	}
+	if size := ReadID3v2Size(encoded); size != len(encoded) {
+		t.Errorf("ReadID3v2Size = %d, want %d", size, len(encoded))
+	}
+	for _, frame := range []string{"TIT2", "TPE1", "COMM", "USLT", "WXXX", "APIC"} {
+		if !bytes.Contains(encoded, []byte(frame)) {
+			t.Errorf("frame %s missing", frame)
+		}
+	}
+	for _, frame := range []string{"TALB", "TDRC", "TCON"} {
+		if bytes.Contains(encoded, []byte(frame)) {
+			t.Errorf("empty frame %s written", frame)
+		}
+	}
+	if !bytes.Contains(encoded, []byte("por\x00Linha 1")) {
+		t.Error("lyrics without lowercase language")
+	}
+	if !bytes.Contains(encoded, []byte("Canção de Louvor")) {
+		t.Error("title not written as UTF-8")
+	}
+}
+
+func TestID3v2RoundTrip(t *testing.T) {
+	audio := append([]byte{0xFF, 0xFB, 0x90, 0x00}, bytes.Repeat([]byte{0x55}, 600)...)
+	small := Tag{Title: "Alegria"}.Encode()
+	large := Tag{Title: "Coragem", Picture: make([]byte, 300*1024), PictureMIME: "image/jpeg"}.Encode()
+
+	tests := []struct {
+		name string
+		tag  []byte
+		size int
+	}{
+		{"untagged", nil, 0},
+		{"small tag", small, len(small)},
+		{"large tag", large, len(large)},
+		{"footer", withFooter(small), len(small) + 10},
+		{"stacked tags", append(append([]byte(nil), small...), large...), len(small)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := append(append([]byte(nil), tt.tag...), audio...)
+			if size := ReadID3v2Size(data); size != tt.size {
+				t.Errorf("ReadID3v2Size = %d, want %d", size, tt.size)
+			}
+
+			reader, err := SkipID3v2(bytes.NewReader(data))
+			if err != nil {
+				t.Fatalf("SkipID3v2: %v", err)
+			}
+			rest, _ := io.ReadAll(reader)
+			if !bytes.Equal(rest, audio) {
+				t.Errorf("SkipID3v2 left %d bytes, want the %d audio bytes", len(rest), len(audio))
+			}
+
+			var out bytes.Buffer
+			retag := Tag{Title: "Esperança"}
+			if err := WriteTagged(&out, bytes.NewReader(data), retag); err != nil {
+				t.Fatalf("WriteTagged: %v", err)
+			}
+			if want := append(retag.Encode(), audio...); !bytes.Equal(out.Bytes(), want) {
+				t.Error("WriteTagged did not replace the tag")
+			}
+		})
+	}
+}
+
+func TestSkipID3v2Truncated(t *testing.T) {
+	tag := Tag{Title: "Alegria", Lyrics: "Uma letra longa o bastante"}.Encode()
+	if _, err := SkipID3v2(bytes.NewReader(tag[:len(tag)-5])); err == nil {
+		t.Error("SkipID3v2 accepted a truncated tag")
+	}
+}
+
+func TestReadID3v2SizeInvalid(t *testing.T) {
+	for name, data := range map[string][]byte{
+		"short":          []byte("ID3\x04"),
+		"not synchsafe":  []byte("ID3\x04\x00\x00\x00\x00\x80\x00"),
+		"other contents": []byte("\xFF\xFB\x90\x00\x00\x00\x00\x00\x00\x00"),
+	} {
+		if size := ReadID3v2Size(data); size != 0 {
+			t.Errorf("%s: ReadID3v2Size = %d, want 0", name, size)
+		}
+	}
+}
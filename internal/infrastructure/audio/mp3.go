@@ -0,0 +1,324 @@
+package audio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/sant0x00/downloader-music/internal/domain"
+)
+
+const (
+	// Garbage tolerated before the first frame (after the ID3 tag) before
+	// the payload is considered not to be MPEG audio.
+	maxLeadingJunk = 64 * 1024
+	minAudioFrames = 2
+	id3v1Size      = 128
+)
+
+var mpegBitrates = [2][3][16]int{
+	{ // MPEG 1: layer I, II, III
+		{0, 32, 64, 96, 128, 160, 192, 224, 256, 288, 320, 352, 384, 416, 448, -1},
+		{0, 32, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320, 384, -1},
+		{0, 32, 40, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320, -1},
+	},
+	{ // MPEG 2 and 2.5: layer I, II, III
+		{0, 32, 48, 56, 64, 80, 96, 112, 128, 144, 160, 176, 192, 224, 256, -1},
+		{0, 8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160, -1},
+		{0, 8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160, -1},
+	},
+}
+
+var mpegSampleRates = map[byte][3]int{
+	3: {44100, 48000, 32000}, // MPEG 1
+	2: {22050, 24000, 16000}, // MPEG 2
+	0: {11025, 12000, 8000},  // MPEG 2.5
+}
+
+type frameHeader struct {
+	version    byte // 3 = MPEG 1, 2 = MPEG 2, 0 = MPEG 2.5
+	layer      int  // 1, 2 or 3
+	bitrate    int  // kbps
+	sampleRate int
+	samples    int
+	length     int
+	mono       bool
+}
+
+func parseFrameHeader(data []byte) (frameHeader, bool) {
+	if len(data) < 4 || data[0] != 0xFF || data[1]&0xE0 != 0xE0 {
+		return frameHeader{}, false
+	}
+
+	version := (data[1] >> 3) & 0x03
+	layerBits := (data[1] >> 1) & 0x03
+	bitrateIndex := data[2] >> 4
+	sampleIndex := (data[2] >> 2) & 0x03
+	if version == 1 || layerBits == 0 || bitrateIndex == 0 || bitrateIndex == 15 || sampleIndex == 3 {
+		// Reserved values, or free format which cannot be walked frame by frame.
+		return frameHeader{}, false
+	}
+
+	header := frameHeader{
+		version: version,
+		layer:   4 - int(layerBits),
+		mono:    data[3]>>6 == 3,
+	}
+
+	table := 1
+	if version == 3 {
+		table = 0
+	}
+	header.bitrate = mpegBitrates[table][header.layer-1][bitrateIndex]
+	header.sampleRate = mpegSampleRates[version][sampleIndex]
+
+	padding := int((data[2] >> 1) & 0x01)
+	switch {
+	case header.layer == 1:
+		header.samples = 384
+		header.length = (12*header.bitrate*1000/header.sampleRate + padding) * 4
+	case header.layer == 3 && version != 3:
+		header.samples = 576
+		header.length = 72*header.bitrate*1000/header.sampleRate + padding
+	default:
+		header.samples = 1152
+		header.length = 144*header.bitrate*1000/header.sampleRate + padding
+	}
+
+	return header, header.length > 4
+}
+
+// sideInfoSize is the size of the layer III side information, which the
+// Xing header follows.
+func (h frameHeader) sideInfoSize() int {
+	switch {
+	case h.version == 3 && h.mono:
+		return 17
+	case h.version == 3:
+		return 32
+	case h.mono:
+		return 9
+	default:
+		return 17
+	}
+}
+
+// MP3Inspector validates MPEG audio as it is written, walking every frame
+// header after the leading ID3v2 tag. It never holds more than one frame in
+// memory, so it can sit next to the file writer during a download.
+type MP3Inspector struct {
+	buf     []byte
+	skip    int64
+	started bool
+
+	frames     int
+	samples    float64 // seconds of audio walked so far
+	audioBytes int64
+	junk       int64
+	bitrates   map[int]bool
+	sampleRate int
+	pending    int64 // bytes of the last frame still missing
+
+	vbrChecked   bool
+	headerFrames int    // frame count announced by a Xing/VBRI header
+	head         []byte // first bytes of the payload, to describe failures
+	err          error
+}
+
+func NewMP3Inspector() *MP3Inspector {
+	return &MP3Inspector{bitrates: make(map[int]bool)}
+}
+
+func (m *MP3Inspector) Write(p []byte) (int, error) {
+	written := len(p)
+	if remaining := 512 - len(m.head); remaining > 0 {
+		if remaining > len(p) {
+			remaining = len(p)
+		}
+		m.head = append(m.head, p[:remaining]...)
+	}
+
+	for len(p) > 0 && m.err == nil {
+		if m.skip > 0 {
+			n := int64(len(p))
+			if n > m.skip {
+				n = m.skip
+			}
+			m.skip -= n
+			m.pending = m.skip
+			p = p[n:]
+			continue
+		}
+
+		m.buf = append(m.buf, p...)
+		p = nil
+		m.consume()
+	}
+
+	return written, nil
+}
+
+func (m *MP3Inspector) consume() {
+	for m.err == nil && m.skip == 0 {
+		if !m.started {
+			if len(m.buf) < id3HeaderSize {
+				return
+			}
+			if bytes.HasPrefix(m.buf, []byte("ID3")) {
+				size := ReadID3v2Size(m.buf)
+				if size == 0 {
+					m.err = fmt.Errorf("%w: cabeçalho ID3 inválido", domain.ErrNotAudio)
+					return
+				}
+				m.discard(int64(size))
+				continue
+			}
+			m.started = true
+		}
+
+		if len(m.buf) < 4 {
+			return
+		}
+
+		header, ok := parseFrameHeader(m.buf)
+		if !ok {
+			m.skipJunk()
+			continue
+		}
+
+		if m.frames == 0 && !m.vbrChecked {
+			// The first frame may carry a Xing/VBRI header instead of audio.
+			if len(m.buf) < header.length {
+				return
+			}
+			m.vbrChecked = true
+			if frames, found := readVBRHeader(m.buf[:header.length], header); found {
+				m.headerFrames = frames
+				m.discard(int64(header.length))
+				continue
+			}
+		}
+
+		m.frames++
+		m.samples += float64(header.samples) / float64(header.sampleRate)
+		m.audioBytes += int64(header.length)
+		m.bitrates[header.bitrate] = true
+		m.sampleRate = header.sampleRate
+		m.discard(int64(header.length))
+		m.pending = m.skip
+	}
+}
+
+func (m *MP3Inspector) skipJunk() {
+	// A trailing ID3v1 tag is the only thing expected after the frames.
+	if m.frames > 0 && bytes.HasPrefix(m.buf, []byte("TAG")) {
+		m.discard(id3v1Size)
+		return
+	}
+
+	next := bytes.IndexByte(m.buf[1:], 0xFF)
+	if next < 0 {
+		next = len(m.buf) - 1
+	}
+	m.junk += int64(next + 1)
+	m.buf = m.buf[next+1:]
+
+	if m.frames == 0 && m.junk > maxLeadingJunk {
+		m.err = fmt.Errorf("%w: nenhum quadro MPEG encontrado", domain.ErrNotAudio)
+	}
+}
+
+func (m *MP3Inspector) discard(n int64) {
+	if int64(len(m.buf)) >= n {
+		m.buf = m.buf[n:]
+		return
+	}
+	m.skip = n - int64(len(m.buf))
+	m.buf = m.buf[:0]
+}
+
+// Finish reports the stream properties once everything has been written.
+func (m *MP3Inspector) Finish() (domain.AudioInfo, error) {
+	if m.err != nil {
+		if sniffText(m.head) {
+			return domain.AudioInfo{}, fmt.Errorf("%w: conteúdo de texto/HTML", domain.ErrNotAudio)
+		}
+		return domain.AudioInfo{}, m.err
+	}
+
+	if m.frames < minAudioFrames {
+		if sniffText(m.head) {
+			return domain.AudioInfo{}, fmt.Errorf("%w: conteúdo de texto/HTML", domain.ErrNotAudio)
+		}
+		return domain.AudioInfo{}, fmt.Errorf("%w: nenhum quadro MPEG encontrado", domain.ErrNotAudio)
+	}
+
+	if m.pending > 0 {
+		return domain.AudioInfo{}, fmt.Errorf("%w: último quadro incompleto (faltam %d bytes)", domain.ErrTruncatedAudio, m.pending)
+	}
+	// Encoders disagree on whether the header frame itself is counted.
+	if m.headerFrames > 0 && m.headerFrames-m.frames > 2 {
+		return domain.AudioInfo{}, fmt.Errorf("%w: %d de %d quadros", domain.ErrTruncatedAudio, m.frames, m.headerFrames)
+	}
+
+	info := domain.AudioInfo{
+		Duration:   m.samples,
+		SampleRate: m.sampleRate,
+		Frames:     m.frames,
+		VBR:        len(m.bitrates) > 1,
+	}
+	if m.samples > 0 {
+		info.Bitrate = int(float64(m.audioBytes*8)/m.samples/1000 + 0.5)
+	}
+	return info, nil
+}
+
+// readVBRHeader looks for a Xing/Info or VBRI header in the first frame and
+// returns the frame count it announces.
+func readVBRHeader(frame []byte, header frameHeader) (int, bool) {
+	offset := 4 + header.sideInfoSize()
+	if len(frame) >= offset+8 {
+		tag := string(frame[offset : offset+4])
+		if tag == "Xing" || tag == "Info" {
+			flags := binary.BigEndian.Uint32(frame[offset+4:])
+			if flags&0x01 != 0 && len(frame) >= offset+12 {
+				return int(binary.BigEndian.Uint32(frame[offset+8:])), true
+			}
+			return 0, true
+		}
+	}
+
+	const vbriOffset = 4 + 32
+	if len(frame) >= vbriOffset+18 && string(frame[vbriOffset:vbriOffset+4]) == "VBRI" {
+		return int(binary.BigEndian.Uint32(frame[vbriOffset+14:])), true
+	}
+
+	return 0, false
+}
+
+func sniffText(data []byte) bool {
+	trimmed := bytes.ToLower(bytes.TrimSpace(data))
+	for _, marker := range []string{"<!doctype", "<html", "<?xml", "{"} {
+		if bytes.HasPrefix(trimmed, []byte(marker)) {
+			return true
+		}
+	}
+	return false
+}
+
+// MP3Analyzer creates an MP3Inspector for every MP3 file.
+type MP3Analyzer struct{}
+
+func NewMP3Analyzer() *MP3Analyzer {
+	return &MP3Analyzer{}
+}
+
+func (a *MP3Analyzer) Supports(filename string) bool {
+	return strings.EqualFold(path.Ext(filename), ".mp3")
+}
+
+func (a *MP3Analyzer) NewInspection() domain.AudioInspection {
+	return NewMP3Inspector()
+}
@@ -0,0 +1,145 @@
+package audio
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/sant0x00/downloader-music/internal/domain"
+)
+
+// MPEG 1 layer III stereo frames at 44.1 kHz, by bitrate index.
+const (
+	bitrate128 = 9  // 417 bytes per frame
+	bitrate160 = 10 // 522 bytes per frame
+)
+
+func mp3Frame(bitrateIndex byte) []byte {
+	header := []byte{0xFF, 0xFB, bitrateIndex << 4, 0x00}
+	parsed, ok := parseFrameHeader(header)
+	if !ok {
+		panic("invalid test frame")
+	}
+	frame := make([]byte, parsed.length)
+	copy(frame, header)
+	return frame
+}
+
+func mp3Frames(bitrates ...byte) []byte {
+	var data []byte
+	for _, bitrate := range bitrates {
+		data = append(data, mp3Frame(bitrate)...)
+	}
+	return data
+}
+
+// xingFrame is a first frame carrying a Xing header that announces frames.
+func xingFrame(frames uint32) []byte {
+	frame := mp3Frame(bitrate128)
+	offset := 4 + 32
+	copy(frame[offset:], "Xing")
+	binary.BigEndian.PutUint32(frame[offset+4:], 0x01)
+	binary.BigEndian.PutUint32(frame[offset+8:], frames)
+	return frame
+}
+
+func repeat(bitrate byte, n int) []byte {
+	bitrates := make([]byte, n)
+	for i := range bitrates {
+		bitrates[i] = bitrate
+	}
+	return mp3Frames(bitrates...)
+}
+
+func TestMP3Inspector(t *testing.T) {
+	cbr := repeat(bitrate128, 10)
+	vbr := append(xingFrame(3), mp3Frames(bitrate128, bitrate160, bitrate128)...)
+	tagged := append(Tag{Title: "Alegria", Picture: make([]byte, 2048)}.Encode(), cbr...)
+	id3v1 := append(append([]byte(nil), cbr...), append([]byte("TAG"), make([]byte, id3v1Size-3)...)...)
+
+	tests := []struct {
+		name    string
+		data    []byte
+		frames  int
+		vbr     bool
+		bitrate int
+		err     error
+	}{
+		{"cbr", cbr, 10, false, 128, nil},
+		{"vbr with xing", vbr, 3, true, 0, nil},
+		{"leading id3v2 tag", tagged, 10, false, 128, nil},
+		{"trailing id3v1 tag", id3v1, 10, false, 128, nil},
+		{"truncated last frame", cbr[:len(cbr)-100], 0, false, 0, domain.ErrTruncatedAudio},
+		{"fewer frames than xing", append(xingFrame(10), mp3Frames(bitrate128, bitrate160)...), 0, false, 0, domain.ErrTruncatedAudio},
+		{"truncated id3v2 tag", tagged[:100], 0, false, 0, domain.ErrNotAudio},
+		{"html page", []byte("<!DOCTYPE html><html><body>Não encontrado</body></html>"), 0, false, 0, domain.ErrNotAudio},
+		{"empty", nil, 0, false, 0, domain.ErrNotAudio},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Downloads arrive in arbitrary chunks, so both splits must agree.
+			for _, chunk := range []int{7, len(tt.data) + 1} {
+				inspector := NewMP3Inspector()
+				for i := 0; i < len(tt.data); i += chunk {
+					inspector.Write(tt.data[i:min(i+chunk, len(tt.data))])
+				}
+
+				info, err := inspector.Finish()
+				if tt.err != nil {
+					if !errors.Is(err, tt.err) {
+						t.Errorf("chunks of %d: error = %v, want %v", chunk, err, tt.err)
+					}
+					continue
+				}
+				if err != nil {
+					t.Fatalf("chunks of %d: Finish: %v", chunk, err)
+				}
+
+				duration := float64(tt.frames) * 1152 / 44100
+				if info.Frames != tt.frames || info.VBR != tt.vbr || info.SampleRate != 44100 || math.Abs(info.Duration-duration) > 1e-9 {
+					t.Errorf("chunks of %d: info = %+v, want %d frames, vbr %v", chunk, info, tt.frames, tt.vbr)
+				}
+				if tt.bitrate > 0 && info.Bitrate != tt.bitrate {
+					t.Errorf("chunks of %d: bitrate = %d, want %d", chunk, info.Bitrate, tt.bitrate)
+				}
+			}
+		})
+	}
+}
+
+func TestParseFrameHeader(t *testing.T) {
+	tests := []struct {
+		name   string
+		header []byte
+		length int
+		ok     bool
+	}{
+		{"128 kbps", []byte{0xFF, 0xFB, 0x90, 0x00}, 417, true},
+		{"128 kbps padded", []byte{0xFF, 0xFB, 0x92, 0x00}, 418, true},
+		{"160 kbps", []byte{0xFF, 0xFB, 0xA0, 0x00}, 522, true},
+		{"free format", []byte{0xFF, 0xFB, 0x00, 0x00}, 0, false},
+		{"reserved sample rate", []byte{0xFF, 0xFB, 0x9C, 0x00}, 0, false},
+		{"no sync", []byte{0xFF, 0x0B, 0x90, 0x00}, 0, false},
+		{"short", []byte{0xFF, 0xFB}, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header, ok := parseFrameHeader(tt.header)
+			if ok != tt.ok || (ok && header.length != tt.length) {
+				t.Errorf("parseFrameHeader(% x) = %d, %v; want %d, %v", tt.header, header.length, ok, tt.length, tt.ok)
+			}
+		})
+	}
+}
+
+func TestMP3AnalyzerSupports(t *testing.T) {
+	analyzer := NewMP3Analyzer()
+	for name, want := range map[string]bool{"Alegria.mp3": true, "Alegria.MP3": true, "Alegria.m4a": false} {
+		if got := analyzer.Supports(name); got != want {
+			t.Errorf("Supports(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
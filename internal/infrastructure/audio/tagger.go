@@ -0,0 +1,121 @@
+package audio
+
+import (
+	"io"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/sant0x00/downloader-music/internal/domain"
+)
+
+// TagTemplates define the value of each frame. Templates may reference
+// {title}, {album}, {year}, {track}, {url}, {id} and {filename}.
+type TagTemplates struct {
+	Title    string
+	Artist   string
+	Album    string
+	Genre    string
+	Language string
+	Comment  string
+}
+
+type ID3Tagger struct {
+	templates TagTemplates
+	covers    domain.CoverSource
+	lyrics    domain.LyricsSource
+	logger    domain.Logger
+}
+
+func NewID3Tagger(templates TagTemplates) *ID3Tagger {
+	return &ID3Tagger{templates: templates}
+}
+
+// SetCoverSource enables embedding the clip artwork as an APIC frame.
+func (t *ID3Tagger) SetCoverSource(covers domain.CoverSource, logger domain.Logger) {
+	t.covers = covers
+	t.logger = logger
+}
+
+// SetLyricsSource enables embedding the lyrics as an USLT frame.
+func (t *ID3Tagger) SetLyricsSource(lyrics domain.LyricsSource, logger domain.Logger) {
+	t.lyrics = lyrics
+	t.logger = logger
+}
+
+// Supports is limited to MP3: M4A files get neither tags nor an embedded
+// cover, only the folder image.
+func (t *ID3Tagger) Supports(clipe domain.ClipeMusical) bool {
+	return strings.EqualFold(path.Ext(clipe.GetSanitizedFilename()), ".mp3")
+}
+
+func (t *ID3Tagger) Tag(dst io.Writer, src io.Reader, clipe domain.ClipeMusical) error {
+	return WriteTagged(dst, src, t.BuildTag(clipe))
+}
+
+func (t *ID3Tagger) BuildTag(clipe domain.ClipeMusical) Tag {
+	tag := Tag{
+		Title:    t.expand(t.templates.Title, clipe),
+		Artist:   t.expand(t.templates.Artist, clipe),
+		Album:    t.expand(t.templates.Album, clipe),
+		Genre:    t.expand(t.templates.Genre, clipe),
+		Language: t.expand(t.templates.Language, clipe),
+		Comment:  t.expand(t.templates.Comment, clipe),
+		URL:      clipe.URL,
+	}
+
+	if clipe.Ano > 0 {
+		tag.Year = strconv.Itoa(clipe.Ano)
+	}
+	if clipe.Faixa > 0 {
+		tag.Track = strconv.Itoa(clipe.Faixa)
+	}
+
+	if t.covers != nil {
+		cover, err := t.covers.FetchCover(clipe)
+		if err != nil {
+			t.logger.Warn("Capa não incorporada", "titulo", clipe.Titulo, "erro", err.Error())
+		} else if cover != nil {
+			tag.Picture = cover.Data
+			tag.PictureMIME = cover.MIMEType
+		}
+	}
+
+	if t.lyrics != nil {
+		lyrics, err := t.lyrics.FetchLyrics(clipe)
+		if err != nil {
+			t.logger.Warn("Letra não incorporada", "titulo", clipe.Titulo, "erro", err.Error())
+		} else {
+			tag.Lyrics = domain.StripLyricsTimings(lyrics)
+		}
+	}
+
+	return tag
+}
+
+func (t *ID3Tagger) expand(template string, clipe domain.ClipeMusical) string {
+	if template == "" {
+		return ""
+	}
+
+	year, track := "", ""
+	if clipe.Ano > 0 {
+		year = strconv.Itoa(clipe.Ano)
+	}
+	if clipe.Faixa > 0 {
+		track = strconv.Itoa(clipe.Faixa)
+	}
+
+	replacer := strings.NewReplacer(
+		"{title}", clipe.Titulo,
+		"{album}", clipe.Album,
+		"{year}", year,
+		"{track}", track,
+		"{url}", clipe.URL,
+		"{id}", clipe.ID,
+		"{filename}", clipe.GetSanitizedFilename(),
+		"{category}", clipe.Categoria,
+		"{description}", clipe.Descricao,
+	)
+	return strings.TrimSpace(replacer.Replace(template))
+}
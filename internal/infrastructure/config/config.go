@@ -3,15 +3,27 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"gopkg.in/yaml.v3"
 )
 
 type Config struct {
-	Download DownloadConfig `yaml:"download"`
-	Scraping ScrapingConfig `yaml:"scraping"`
-	Logging  LoggingConfig  `yaml:"logging"`
+	Download  DownloadConfig  `yaml:"download"`
+	Scraping  ScrapingConfig  `yaml:"scraping"`
+	Logging   LoggingConfig   `yaml:"logging"`
+	Library   LibraryConfig   `yaml:"library"`
+	Storage   StorageConfig   `yaml:"storage"`
+	Tags      TagsConfig      `yaml:"tags"`
+	Cover     CoverConfig     `yaml:"cover"`
+	Playlists PlaylistsConfig `yaml:"playlists"`
+	Lyrics    LyricsConfig    `yaml:"lyrics"`
+	Cache     CacheConfig     `yaml:"cache"`
+	// Publications lists jw.org audio publications besides the music videos.
+	Publications []PublicationConfig `yaml:"publications"`
+	// Categories are checked before the built-in series.
+	Categories []CategoryConfig `yaml:"categories"`
 }
 
 type DownloadConfig struct {
@@ -19,12 +31,144 @@ type DownloadConfig struct {
 	RetryAttempts     int    `yaml:"retry_attempts"`
 	TimeoutSeconds    int    `yaml:"timeout_seconds"`
 	OutputDirectory   string `yaml:"output_directory"`
+	FilenameStyle     string `yaml:"filename_style"`
+	// YearSource is title, publication or convention.
+	YearSource string `yaml:"year_source"`
+	// FolderLayout arranges the year folders, e.g. "{category}/{year}".
+	FolderLayout string `yaml:"folder_layout"`
 }
 
 type ScrapingConfig struct {
 	BaseURL              string        `yaml:"base_url"`
 	DelayBetweenRequests time.Duration `yaml:"delay_between_requests"`
 	UserAgent            string        `yaml:"user_agent"`
+	// Languages are jw.org language codes (T = Portuguese, E = English, ...).
+	Languages    []string          `yaml:"languages"`
+	LanguageMode string            `yaml:"language_mode"`
+	ListingURLs  map[string]string `yaml:"listing_urls"`
+	// Mode is html (listing page matched to the API) or api (API only).
+	Mode           string `yaml:"mode"`
+	EnrichFromHTML bool   `yaml:"enrich_from_html"`
+	// MaxPages caps the listing pages followed per language.
+	MaxPages int `yaml:"max_pages"`
+	// DetailPages reads the page of each clip, one request per clip.
+	DetailPages bool `yaml:"detail_pages"`
+	// RequestsPerSecond limits all scraper requests together; zero derives
+	// it from DelayBetweenRequests.
+	RequestsPerSecond float64 `yaml:"requests_per_second"`
+	Burst             int     `yaml:"burst"`
+	// DetailWorkers fetches the details of that many clips at once.
+	DetailWorkers int `yaml:"detail_workers"`
+	// Source overrides how the site is read; empty fields keep the
+	// built-in values.
+	Source SourceConfig `yaml:"source"`
+}
+
+type SourceConfig struct {
+	ListingLink   string   `yaml:"listing_link,omitempty"`
+	ListingItem   string   `yaml:"listing_item,omitempty"`
+	ListingImages string   `yaml:"listing_images,omitempty"`
+	NextPage      []string `yaml:"next_page,omitempty"`
+	Lyrics        []string `yaml:"lyrics,omitempty"`
+	Description   []string `yaml:"description,omitempty"`
+	Images        []string `yaml:"images,omitempty"`
+	Duration      []string `yaml:"duration,omitempty"`
+	Published     []string `yaml:"published,omitempty"`
+	PubMediaURL   string   `yaml:"pub_media_url,omitempty"`
+	FinderURL     string   `yaml:"finder_url,omitempty"`
+	// TitleCleanup replaces the built-in rules when given.
+	TitleCleanup []string          `yaml:"title_cleanup,omitempty"`
+	APIFields    map[string]string `yaml:"api_fields,omitempty"`
+	// Languages and TrackMedia are merged with the built-in entries.
+	Languages           map[string]SourceLanguageConfig `yaml:"languages,omitempty"`
+	ImageSizeAttributes []string                        `yaml:"image_size_attributes,omitempty"`
+	RelatedArea         string                          `yaml:"related_area,omitempty"`
+	SongbookPub         string                          `yaml:"songbook_pub,omitempty"`
+	SongReference       string                          `yaml:"song_reference,omitempty"`
+	TrackQuery          string                          `yaml:"track_query,omitempty"`
+	TrackMedia          map[string]string               `yaml:"track_media,omitempty"`
+	DefaultTrackMedia   string                          `yaml:"default_track_media,omitempty"`
+}
+
+type SourceLanguageConfig struct {
+	Locale     string `yaml:"locale,omitempty"`
+	ListingURL string `yaml:"listing_url,omitempty"`
+}
+
+type LibraryConfig struct {
+	DedupePolicy string `yaml:"dedupe_policy"`
+	PrunePolicy  string `yaml:"prune_policy"`
+}
+
+type StorageConfig struct {
+	Backend string   `yaml:"backend"`
+	S3      S3Config `yaml:"s3"`
+}
+
+type S3Config struct {
+	Endpoint        string `yaml:"endpoint"`
+	Region          string `yaml:"region"`
+	Bucket          string `yaml:"bucket"`
+	Prefix          string `yaml:"prefix"`
+	AccessKeyID     string `yaml:"access_key_id"`
+	SecretAccessKey string `yaml:"secret_access_key"`
+	UsePathStyle    bool   `yaml:"use_path_style"`
+	PartSizeMB      int    `yaml:"part_size_mb"`
+}
+
+type TagsConfig struct {
+	Enabled  bool   `yaml:"enabled"`
+	Title    string `yaml:"title"`
+	Artist   string `yaml:"artist"`
+	Album    string `yaml:"album"`
+	Genre    string `yaml:"genre"`
+	Language string `yaml:"language"`
+	Comment  string `yaml:"comment"`
+}
+
+type CoverConfig struct {
+	Enabled     bool   `yaml:"enabled"`
+	Embed       bool   `yaml:"embed"`
+	FolderImage string `yaml:"folder_image"`
+	MaxBytes    int64  `yaml:"max_bytes"`
+}
+
+type PlaylistsConfig struct {
+	Enabled  bool     `yaml:"enabled"`
+	Formats  []string `yaml:"formats"`
+	PerYear  bool     `yaml:"per_year"`
+	Library  bool     `yaml:"library"`
+	NewInRun bool     `yaml:"new_in_run"`
+}
+
+type LyricsConfig struct {
+	Enabled bool `yaml:"enabled"`
+	Sidecar bool `yaml:"sidecar"`
+	Embed   bool `yaml:"embed"`
+}
+
+// CacheConfig keeps the scraper responses on disk. Within TTL they are reused
+// without asking jw.org; afterwards they are revalidated.
+type CacheConfig struct {
+	Enabled   bool          `yaml:"enabled"`
+	Directory string        `yaml:"directory"`
+	TTL       time.Duration `yaml:"ttl"`
+}
+
+type PublicationConfig struct {
+	Code string `yaml:"code"`
+	// Folder defaults to the publication code.
+	Folder  string   `yaml:"folder"`
+	Formats []string `yaml:"formats"`
+}
+
+// CategoryConfig classifies the clips matching all of its regular
+// expressions.
+type CategoryConfig struct {
+	Name        string `yaml:"name"`
+	Title       string `yaml:"title"`
+	URL         string `yaml:"url"`
+	Publication string `yaml:"publication"`
 }
 
 type LoggingConfig struct {
@@ -39,38 +183,96 @@ func LoadConfig(configPath string) (*Config, error) {
 			RetryAttempts:     3,
 			TimeoutSeconds:    30,
 			OutputDirectory:   "~/Downloads/ClipesJW",
+			FilenameStyle:     "ascii-snake",
+			YearSource:        "title",
 		},
 		Scraping: ScrapingConfig{
 			BaseURL:              "https://www.jw.org/pt/biblioteca/musica-canticos/clipes-musicais/",
 			DelayBetweenRequests: time.Second,
 			UserAgent:            "ClipesJW-Downloader/1.0",
+			Languages:            []string{"T"},
+			LanguageMode:         "all",
+			Mode:                 "html",
+			EnrichFromHTML:       true,
+			MaxPages:             20,
+			Burst:                1,
+			DetailWorkers:        4,
 		},
 		Logging: LoggingConfig{
 			Level:      "info",
 			OutputFile: "downloader.log",
 		},
+		Library: LibraryConfig{
+			DedupePolicy: "report",
+			PrunePolicy:  "archive",
+		},
+		Storage: StorageConfig{
+			Backend: "filesystem",
+			S3: S3Config{
+				Region:       "us-east-1",
+				Prefix:       "ClipesJW",
+				UsePathStyle: true,
+				PartSizeMB:   8,
+			},
+		},
+		Tags: TagsConfig{
+			Enabled:  true,
+			Title:    "{title}",
+			Artist:   "JW.org",
+			Album:    "{album}",
+			Genre:    "Música Cristã",
+			Language: "por",
+			Comment:  "{url}",
+		},
+		Cover: CoverConfig{
+			Enabled:     true,
+			Embed:       true,
+			FolderImage: "cover.jpg",
+			MaxBytes:    512 * 1024,
+		},
+		Playlists: PlaylistsConfig{
+			Enabled:  true,
+			Formats:  []string{"m3u8"},
+			PerYear:  true,
+			Library:  true,
+			NewInRun: true,
+		},
+		Lyrics: LyricsConfig{
+			Enabled: false,
+			Sidecar: true,
+			Embed:   true,
+		},
+		Cache: CacheConfig{
+			Enabled:   false,
+			Directory: "~/.cache/ClipesJW",
+			TTL:       30 * time.Minute,
+		},
 	}
 
 	if _, err := os.Stat(configPath); os.IsNotExist(err) {
-		return config, SaveConfig(config, configPath)
-	}
-
-	data, err := os.ReadFile(configPath)
-	if err != nil {
-		return nil, err
-	}
+		// The saved defaults keep "~", so the file stays valid for the user.
+		if err := SaveConfig(config, configPath); err != nil {
+			return nil, err
+		}
+	} else {
+		data, err := os.ReadFile(configPath)
+		if err != nil {
+			return nil, err
+		}
 
-	err = yaml.Unmarshal(data, config)
-	if err != nil {
-		return nil, err
+		if err := yaml.Unmarshal(data, config); err != nil {
+			return nil, err
+		}
 	}
 
-	if config.Download.OutputDirectory[0] == '~' {
-		homeDir, err := os.UserHomeDir()
-		if err != nil {
-			return nil, err
+	for _, dir := range []*string{&config.Download.OutputDirectory, &config.Cache.Directory} {
+		if strings.HasPrefix(*dir, "~") {
+			homeDir, err := os.UserHomeDir()
+			if err != nil {
+				return nil, err
+			}
+			*dir = filepath.Join(homeDir, (*dir)[1:])
 		}
-		config.Download.OutputDirectory = filepath.Join(homeDir, config.Download.OutputDirectory[1:])
 	}
 
 	return config, nil
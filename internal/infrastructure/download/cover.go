@@ -0,0 +1,121 @@
+package download
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sant0x00/downloader-music/internal/domain"
+)
+
+// CoverFetcher downloads clip artwork. Images are never resized: the largest
+// existing rendition within maxBytes wins, and every URL is fetched at most
+// once per run.
+type CoverFetcher struct {
+	client    *http.Client
+	userAgent string
+	maxBytes  int64
+	logger    domain.Logger
+	mu        sync.Mutex
+	cache     map[string]*domain.CoverImage
+}
+
+func NewCoverFetcher(userAgent string, maxBytes int64, timeoutSeconds int, logger domain.Logger) *CoverFetcher {
+	return &CoverFetcher{
+		client: &http.Client{
+			Timeout: time.Duration(timeoutSeconds) * time.Second,
+		},
+		userAgent: userAgent,
+		maxBytes:  maxBytes,
+		logger:    logger,
+		cache:     make(map[string]*domain.CoverImage),
+	}
+}
+
+func (f *CoverFetcher) FetchCover(clipe domain.ClipeMusical) (*domain.CoverImage, error) {
+	if len(clipe.Imagens) == 0 {
+		return nil, nil
+	}
+
+	for _, url := range clipe.Imagens {
+		image, err := f.fetch(url)
+		if err != nil {
+			f.logger.Debug("Capa descartada", "url", url, "motivo", err.Error())
+			continue
+		}
+		return image, nil
+	}
+
+	return nil, fmt.Errorf("nenhuma capa dentro do limite de %d bytes para %s", f.maxBytes, clipe.Titulo)
+}
+
+func (f *CoverFetcher) fetch(url string) (*domain.CoverImage, error) {
+	f.mu.Lock()
+	if image, cached := f.cache[url]; cached {
+		f.mu.Unlock()
+		if image == nil {
+			return nil, fmt.Errorf("rendição já descartada")
+		}
+		return image, nil
+	}
+	f.mu.Unlock()
+
+	image, err := f.download(url)
+
+	f.mu.Lock()
+	f.cache[url] = image
+	f.mu.Unlock()
+
+	return image, err
+}
+
+func (f *CoverFetcher) download(url string) (*domain.CoverImage, error) {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao criar requisição: %w", err)
+	}
+	req.Header.Set("User-Agent", f.userAgent)
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao fazer requisição: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("status code inválido: %d", resp.StatusCode)
+	}
+	if f.maxBytes > 0 && resp.ContentLength > f.maxBytes {
+		return nil, fmt.Errorf("imagem com %d bytes excede o limite", resp.ContentLength)
+	}
+
+	reader := io.Reader(resp.Body)
+	if f.maxBytes > 0 {
+		reader = io.LimitReader(resp.Body, f.maxBytes+1)
+	}
+
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao baixar imagem: %w", err)
+	}
+	if f.maxBytes > 0 && int64(len(data)) > f.maxBytes {
+		return nil, fmt.Errorf("imagem excede o limite de %d bytes", f.maxBytes)
+	}
+
+	mimeType := resp.Header.Get("Content-Type")
+	if i := strings.Index(mimeType, ";"); i >= 0 {
+		mimeType = mimeType[:i]
+	}
+	if !strings.HasPrefix(mimeType, "image/") {
+		mimeType = http.DetectContentType(data)
+	}
+	if !strings.HasPrefix(mimeType, "image/") {
+		return nil, fmt.Errorf("conteúdo não é uma imagem: %s", mimeType)
+	}
+
+	f.logger.Debug("Capa baixada", "url", url, "bytes", len(data), "tipo", mimeType)
+	return &domain.CoverImage{URL: url, MIMEType: mimeType, Data: data}, nil
+}
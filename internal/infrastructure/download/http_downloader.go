@@ -4,25 +4,25 @@ import (
 	"fmt"
 	"io"
 	"net/http"
-	"os"
 	"sync"
 	"time"
 
 	"github.com/cheggaaa/pb/v3"
 	"github.com/sant0x00/downloader-music/internal/domain"
-	"github.com/sant0x00/downloader-music/internal/infrastructure/storage"
 )
 
 type HTTPDownloader struct {
 	client            *http.Client
-	repository        *storage.FileSystemRepository
+	repository        domain.ClipeRepository
 	logger            domain.Logger
 	concurrentWorkers int
 	retryAttempts     int
 	progressCallback  func(current, total int64, filename string)
+	tagger            domain.AudioTagger
+	inspector         domain.AudioInspector
 }
 
-func NewHTTPDownloader(repository *storage.FileSystemRepository, logger domain.Logger, concurrentWorkers, retryAttempts int, timeoutSeconds int) *HTTPDownloader {
+func NewHTTPDownloader(repository domain.ClipeRepository, logger domain.Logger, concurrentWorkers, retryAttempts int, timeoutSeconds int) *HTTPDownloader {
 	return &HTTPDownloader{
 		client: &http.Client{
 			Timeout: time.Duration(timeoutSeconds) * time.Second,
@@ -38,30 +38,44 @@ func (d *HTTPDownloader) SetProgressCallback(callback func(current, total int64,
 	d.progressCallback = callback
 }
 
+func (d *HTTPDownloader) SetTagger(tagger domain.AudioTagger) {
+	d.tagger = tagger
+}
+
+// SetInspector validates every download before it is committed, so truncated
+// files and error pages never end up in the library.
+func (d *HTTPDownloader) SetInspector(inspector domain.AudioInspector) {
+	d.inspector = inspector
+}
+
 func (d *HTTPDownloader) Download(clipe domain.ClipeMusical, destPath string) error {
+	return d.download(clipe, false)
+}
+
+// download fetches a clip, skipping it when its file exists unless replace
+// is set. The writer commits atomically, so a failed replacement keeps the
+// old file.
+func (d *HTTPDownloader) download(clipe domain.ClipeMusical, replace bool) error {
 	if clipe.URLDownload == "" {
 		return fmt.Errorf("URL de download não encontrada para o clipe: %s", clipe.Titulo)
 	}
 
-	filename := clipe.GetSanitizedFilename()
-	if d.repository.Exists(filename) {
-		d.logger.Info("Arquivo já existe, pulando", "arquivo", filename)
+	if !replace && d.repository.Exists(clipe.GetRelativePath()) {
+		d.logger.Info("Arquivo já existe, pulando", "arquivo", clipe.GetRelativePath())
 		return nil
 	}
 
-	err := d.repository.CreateDirectoryStructure(clipe)
-	if err != nil {
-		return err
-	}
-
-	filePath := d.repository.GetClipeFilePath(clipe)
-
-	d.logger.Info("Iniciando download", "titulo", clipe.Titulo, "url", clipe.URLDownload, "destino", filePath)
+	d.logger.Info("Iniciando download", "titulo", clipe.Titulo, "url", clipe.URLDownload, "destino", clipe.GetRelativePath())
 
+	var err error
 	for attempt := 1; attempt <= d.retryAttempts; attempt++ {
-		err = d.downloadFile(clipe.URLDownload, filePath, clipe.Titulo)
+		var location string
+		location, err = d.downloadFile(&clipe)
 		if err == nil {
-			d.logger.Info("Download concluído", "titulo", clipe.Titulo, "arquivo", filePath)
+			d.logger.Info("Download concluído", "titulo", clipe.Titulo, "arquivo", location)
+			if err := d.repository.Save(clipe); err != nil {
+				d.logger.Warn("Falha ao registrar clipe no manifesto", "titulo", clipe.Titulo, "erro", err.Error())
+			}
 			return nil
 		}
 
@@ -75,6 +89,14 @@ func (d *HTTPDownloader) Download(clipe domain.ClipeMusical, destPath string) er
 }
 
 func (d *HTTPDownloader) DownloadBatch(clipes []domain.ClipeMusical, destPath string) error {
+	return d.batch(clipes, false)
+}
+
+func (d *HTTPDownloader) ReplaceBatch(clipes []domain.ClipeMusical, destPath string) error {
+	return d.batch(clipes, true)
+}
+
+func (d *HTTPDownloader) batch(clipes []domain.ClipeMusical, replace bool) error {
 	d.logger.Info("Iniciando download em lote", "total_clipes", len(clipes), "workers", d.concurrentWorkers)
 
 	jobs := make(chan domain.ClipeMusical, len(clipes))
@@ -86,7 +108,7 @@ func (d *HTTPDownloader) DownloadBatch(clipes []domain.ClipeMusical, destPath st
 		go func() {
 			defer wg.Done()
 			for clipe := range jobs {
-				err := d.Download(clipe, destPath)
+				err := d.download(clipe, replace)
 				results <- err
 			}
 		}()
@@ -120,30 +142,28 @@ func (d *HTTPDownloader) DownloadBatch(clipes []domain.ClipeMusical, destPath st
 	return nil
 }
 
-func (d *HTTPDownloader) downloadFile(url, filePath, titulo string) error {
-	req, err := http.NewRequest("GET", url, nil)
+func (d *HTTPDownloader) downloadFile(clipe *domain.ClipeMusical) (string, error) {
+	req, err := http.NewRequest("GET", clipe.URLDownload, nil)
 	if err != nil {
-		return fmt.Errorf("erro ao criar requisição: %w", err)
+		return "", fmt.Errorf("erro ao criar requisição: %w", err)
 	}
 
 	req.Header.Set("User-Agent", "ClipesJW-Downloader/1.0")
 
 	resp, err := d.client.Do(req)
 	if err != nil {
-		return fmt.Errorf("erro ao fazer requisição: %w", err)
+		return "", fmt.Errorf("erro ao fazer requisição: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("status code inválido: %d", resp.StatusCode)
+		return "", fmt.Errorf("status code inválido: %d", resp.StatusCode)
 	}
 
-	tempFile := filePath + ".tmp"
-	out, err := os.Create(tempFile)
+	out, err := d.repository.OpenWriter(*clipe)
 	if err != nil {
-		return fmt.Errorf("erro ao criar arquivo: %w", err)
+		return "", err
 	}
-	defer out.Close()
 
 	contentLength := resp.ContentLength
 
@@ -155,21 +175,57 @@ func (d *HTTPDownloader) downloadFile(url, filePath, titulo string) error {
 		defer bar.Finish()
 	}
 
-	_, err = io.Copy(out, progressReader)
-	if err != nil {
-		os.Remove(tempFile) // Clean up temporary file
-		return fmt.Errorf("erro ao baixar arquivo: %w", err)
+	hash := domain.NewPayloadHash()
+	counter := &countingWriter{}
+	writers := []io.Writer{out, hash, counter}
+
+	var inspection domain.AudioInspection
+	if d.inspector != nil && d.inspector.Supports(clipe.GetSanitizedFilename()) {
+		inspection = d.inspector.NewInspection()
+		writers = append(writers, inspection)
 	}
+	dst := io.MultiWriter(writers...)
 
-	err = os.Rename(tempFile, filePath)
+	if d.tagger != nil && d.tagger.Supports(*clipe) {
+		err = d.tagger.Tag(dst, progressReader, *clipe)
+		clipe.Tagged = err == nil
+	} else {
+		_, err = io.Copy(dst, progressReader)
+	}
 	if err != nil {
-		os.Remove(tempFile) // Clean up temporary file
-		return fmt.Errorf("erro ao finalizar arquivo: %w", err)
+		out.Abort() // Clean up partial content
+		return "", fmt.Errorf("erro ao baixar arquivo: %w", err)
+	}
+
+	if inspection != nil {
+		info, err := inspection.Finish()
+		if err != nil {
+			out.Abort()
+			return "", fmt.Errorf("arquivo baixado inválido: %w", err)
+		}
+		clipe.Duracao = info.Duration
+		clipe.Bitrate = info.Bitrate
 	}
 
+	if err := out.Commit(); err != nil {
+		return "", err
+	}
+
+	clipe.TamanhoArquivo = counter.n
+	clipe.SHA256 = hash.Sum()
+
 	if d.progressCallback != nil && contentLength > 0 {
-		d.progressCallback(contentLength, contentLength, titulo)
+		d.progressCallback(contentLength, contentLength, clipe.Titulo)
 	}
 
-	return nil
+	return out.Location(), nil
+}
+
+type countingWriter struct {
+	n int64
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.n += int64(len(p))
+	return len(p), nil
 }
@@ -4,7 +4,8 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
-	"strconv"
+	"sync"
+	"time"
 
 	"github.com/sant0x00/downloader-music/internal/domain"
 )
@@ -12,6 +13,8 @@ import (
 type FileSystemRepository struct {
 	outputDirectory string
 	logger          domain.Logger
+	mu              sync.Mutex
+	manifest        *domain.Manifest
 }
 
 func NewFileSystemRepository(outputDirectory string, logger domain.Logger) *FileSystemRepository {
@@ -21,33 +24,91 @@ func NewFileSystemRepository(outputDirectory string, logger domain.Logger) *File
 	}
 }
 
-// TODO: Implement FindAll method to list all clips in the filesystem
 func (r *FileSystemRepository) FindAll() ([]domain.ClipeMusical, error) {
-	return []domain.ClipeMusical{}, nil
+	manifest, err := r.ReadManifest()
+	if err != nil {
+		return nil, err
+	}
+
+	var clipes []domain.ClipeMusical
+	for _, entry := range manifest.SortedEntries() {
+		clipes = append(clipes, entry.ToClipe())
+	}
+
+	return clipes, nil
 }
 
 func (r *FileSystemRepository) Save(clipe domain.ClipeMusical) error {
 	r.logger.Debug("Salvando clipe", "titulo", clipe.Titulo, "arquivo", clipe.GetSanitizedFilename())
-	return nil
-}
 
-func (r *FileSystemRepository) Exists(filename string) bool {
-	dirs := []string{"2025", "2024", "2023", "2022", "outros"}
+	filePath := r.GetClipeFilePath(clipe)
+	info, err := os.Stat(filePath)
+	if err != nil {
+		return fmt.Errorf("erro ao acessar arquivo baixado: %w", err)
+	}
 
-	for _, dir := range dirs {
-		fullPath := filepath.Join(r.outputDirectory, dir, filename)
-		if _, err := os.Stat(fullPath); err == nil {
-			r.logger.Debug("Arquivo já existe", "path", fullPath)
-			return true
+	relPath, err := filepath.Rel(r.outputDirectory, filePath)
+	if err != nil {
+		return err
+	}
+	relPath = filepath.ToSlash(relPath)
+
+	checksum := clipe.SHA256
+	if checksum == "" {
+		checksum, err = r.HashFile(relPath)
+		if err != nil {
+			return err
 		}
 	}
 
-	fullPath := filepath.Join(r.outputDirectory, filename)
-	if _, err := os.Stat(fullPath); err == nil {
-		r.logger.Debug("Arquivo já existe", "path", fullPath)
+	return r.UpdateManifest(func(manifest *domain.Manifest) {
+		manifest.Put(domain.ManifestEntry{
+			Path:           relPath,
+			ID:             clipe.ID,
+			DocID:          clipe.DocID,
+			Titulo:         clipe.Titulo,
+			URL:            clipe.URL,
+			URLDownload:    clipe.URLDownload,
+			Ano:            clipe.Ano,
+			Album:          clipe.Album,
+			Faixa:          clipe.Faixa,
+			Imagens:        clipe.Imagens,
+			Size:           info.Size(),
+			SHA256:         checksum,
+			Tagged:         clipe.Tagged,
+			Duracao:        clipe.Duracao,
+			Bitrate:        clipe.Bitrate,
+			Idioma:         clipe.Idioma,
+			Chave:          clipe.Chave,
+			Publicacao:     clipe.Publicacao,
+			DataPublicacao: domain.OptionalTime(clipe.DataPublicacao),
+			Categoria:      clipe.Categoria,
+			Descricao:      clipe.Descricao,
+			Relacionados:   clipe.Relacionados,
+			DownloadedAt:   time.Now(),
+		})
+	})
+}
+
+func (r *FileSystemRepository) Exists(relPath string) bool {
+	if _, err := os.Stat(r.absPath(relPath)); err == nil {
+		r.logger.Debug("Arquivo já existe", "path", relPath)
 		return true
 	}
 
+	manifest, err := r.ReadManifest()
+	if err != nil {
+		r.logger.Warn("Não foi possível ler o manifesto", "erro", err.Error())
+		return false
+	}
+
+	if canonical, isAlias := manifest.ResolveAlias(relPath); isAlias {
+		if _, err := os.Stat(r.absPath(canonical)); err == nil {
+			r.logger.Debug("Arquivo já existe sob outro nome", "arquivo", relPath, "canonico", canonical)
+			return true
+		}
+	}
+
 	return false
 }
 
@@ -56,13 +117,7 @@ func (r *FileSystemRepository) GetOutputDirectory() string {
 }
 
 func (r *FileSystemRepository) CreateDirectoryStructure(clipe domain.ClipeMusical) error {
-	var targetDir string
-
-	if clipe.Ano > 0 {
-		targetDir = filepath.Join(r.outputDirectory, strconv.Itoa(clipe.Ano))
-	} else {
-		targetDir = filepath.Join(r.outputDirectory, "outros")
-	}
+	targetDir := filepath.Join(r.outputDirectory, filepath.FromSlash(clipe.GetDirectoryPath()))
 
 	err := os.MkdirAll(targetDir, 0755)
 	if err != nil {
@@ -75,13 +130,65 @@ func (r *FileSystemRepository) CreateDirectoryStructure(clipe domain.ClipeMusica
 }
 
 func (r *FileSystemRepository) GetClipeFilePath(clipe domain.ClipeMusical) string {
-	var targetDir string
+	return filepath.Join(r.outputDirectory, filepath.FromSlash(clipe.GetRelativePath()))
+}
 
-	if clipe.Ano > 0 {
-		targetDir = filepath.Join(r.outputDirectory, strconv.Itoa(clipe.Ano))
-	} else {
-		targetDir = filepath.Join(r.outputDirectory, "outros")
+func (r *FileSystemRepository) OpenWriter(clipe domain.ClipeMusical) (domain.ClipeWriter, error) {
+	if err := r.CreateDirectoryStructure(clipe); err != nil {
+		return nil, err
 	}
 
-	return filepath.Join(targetDir, clipe.GetSanitizedFilename())
+	return r.newFileWriter(r.GetClipeFilePath(clipe))
+}
+
+func (r *FileSystemRepository) CreateFile(path string) (domain.ClipeWriter, error) {
+	filePath := r.absPath(path)
+	if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+		return nil, fmt.Errorf("erro ao criar diretório: %w", err)
+	}
+
+	return r.newFileWriter(filePath)
+}
+
+func (r *FileSystemRepository) newFileWriter(filePath string) (domain.ClipeWriter, error) {
+	tempFile := filePath + ".tmp"
+	out, err := os.Create(tempFile)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao criar arquivo: %w", err)
+	}
+
+	return &fileClipeWriter{file: out, tempPath: tempFile, finalPath: filePath}, nil
+}
+
+type fileClipeWriter struct {
+	file      *os.File
+	tempPath  string
+	finalPath string
+}
+
+func (w *fileClipeWriter) Write(p []byte) (int, error) {
+	return w.file.Write(p)
+}
+
+func (w *fileClipeWriter) Location() string {
+	return w.finalPath
+}
+
+func (w *fileClipeWriter) Commit() error {
+	if err := w.file.Close(); err != nil {
+		os.Remove(w.tempPath) // Clean up temporary file
+		return fmt.Errorf("erro ao finalizar arquivo: %w", err)
+	}
+
+	if err := os.Rename(w.tempPath, w.finalPath); err != nil {
+		os.Remove(w.tempPath) // Clean up temporary file
+		return fmt.Errorf("erro ao finalizar arquivo: %w", err)
+	}
+
+	return nil
+}
+
+func (w *fileClipeWriter) Abort() error {
+	w.file.Close()
+	return os.Remove(w.tempPath)
 }
@@ -0,0 +1,300 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/sant0x00/downloader-music/internal/domain"
+)
+
+const (
+	ManifestFilename  = ".manifest.json"
+	ActionLogFilename = ".actions.log"
+	ArchiveDirectory  = "archive"
+)
+
+var audioExtensions = map[string]bool{
+	".mp3": true,
+	".m4a": true,
+	".aac": true,
+}
+
+func (r *FileSystemRepository) ListFiles() ([]domain.LibraryFile, error) {
+	var files []domain.LibraryFile
+
+	err := filepath.WalkDir(r.outputDirectory, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if path == r.outputDirectory && os.IsNotExist(err) {
+				return filepath.SkipDir
+			}
+			return err
+		}
+
+		if strings.HasPrefix(entry.Name(), ".") && path != r.outputDirectory {
+			if entry.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if entry.IsDir() && path == filepath.Join(r.outputDirectory, ArchiveDirectory) {
+			return filepath.SkipDir
+		}
+
+		if entry.IsDir() || !audioExtensions[strings.ToLower(filepath.Ext(path))] {
+			return nil
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+
+		relPath, err := filepath.Rel(r.outputDirectory, path)
+		if err != nil {
+			return err
+		}
+
+		files = append(files, domain.LibraryFile{
+			Path:    filepath.ToSlash(relPath),
+			Size:    info.Size(),
+			ModTime: info.ModTime(),
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("erro ao listar biblioteca: %w", err)
+	}
+
+	return files, nil
+}
+
+func (r *FileSystemRepository) HashFile(path string) (string, error) {
+	file, err := os.Open(r.absPath(path))
+	if err != nil {
+		return "", fmt.Errorf("erro ao abrir arquivo: %w", err)
+	}
+	defer file.Close()
+
+	hash := domain.NewPayloadHash()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", fmt.Errorf("erro ao calcular hash de %s: %w", path, err)
+	}
+
+	return hash.Sum(), nil
+}
+
+func (r *FileSystemRepository) OpenFile(path string) (io.ReadCloser, error) {
+	file, err := os.Open(r.absPath(path))
+	if err != nil {
+		return nil, fmt.Errorf("erro ao abrir arquivo: %w", err)
+	}
+	return file, nil
+}
+
+func (r *FileSystemRepository) FileSize(path string) (int64, error) {
+	info, err := os.Stat(r.absPath(path))
+	if err != nil {
+		return 0, fmt.Errorf("erro ao acessar arquivo: %w", err)
+	}
+	return info.Size(), nil
+}
+
+func (r *FileSystemRepository) FindSidecars(path string) ([]string, error) {
+	dir := filepath.Dir(r.absPath(path))
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao listar diretório: %w", err)
+	}
+
+	stem := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	relDir := filepath.ToSlash(filepath.Dir(filepath.FromSlash(path)))
+
+	var sidecars []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || name == filepath.Base(path) || audioExtensions[strings.ToLower(filepath.Ext(name))] {
+			continue
+		}
+		if strings.TrimSuffix(name, filepath.Ext(name)) == stem {
+			sidecars = append(sidecars, relDir+"/"+name)
+		}
+	}
+
+	return sidecars, nil
+}
+
+func (r *FileSystemRepository) FileExists(path string) bool {
+	_, err := os.Stat(r.absPath(path))
+	return err == nil
+}
+
+func (r *FileSystemRepository) LinkDuplicate(canonical, duplicate string) error {
+	canonicalPath := r.absPath(canonical)
+	duplicatePath := r.absPath(duplicate)
+
+	canonicalInfo, err := os.Stat(canonicalPath)
+	if err != nil {
+		return fmt.Errorf("erro ao acessar arquivo canônico: %w", err)
+	}
+	duplicateInfo, err := os.Stat(duplicatePath)
+	if err != nil {
+		return fmt.Errorf("erro ao acessar duplicata: %w", err)
+	}
+	if os.SameFile(canonicalInfo, duplicateInfo) {
+		return nil
+	}
+
+	// Link next to the duplicate first so the replacement is atomic.
+	tempPath := duplicatePath + ".link"
+	os.Remove(tempPath)
+	if err := os.Link(canonicalPath, tempPath); err != nil {
+		return fmt.Errorf("erro ao criar hardlink: %w", err)
+	}
+
+	if err := os.Rename(tempPath, duplicatePath); err != nil {
+		os.Remove(tempPath)
+		return fmt.Errorf("erro ao substituir duplicata: %w", err)
+	}
+
+	r.logger.Debug("Duplicata substituída por hardlink", "canonico", canonical, "duplicata", duplicate)
+	return nil
+}
+
+func (r *FileSystemRepository) RemoveFile(path string) error {
+	if err := os.Remove(r.absPath(path)); err != nil {
+		return fmt.Errorf("erro ao remover arquivo: %w", err)
+	}
+
+	r.logger.Debug("Arquivo removido", "path", path)
+	return nil
+}
+
+func (r *FileSystemRepository) ArchiveFile(path string) (string, error) {
+	archivedPath := filepath.ToSlash(filepath.Join(ArchiveDirectory, filepath.FromSlash(path)))
+	if _, err := os.Stat(r.absPath(archivedPath)); err == nil {
+		ext := filepath.Ext(archivedPath)
+		archivedPath = fmt.Sprintf("%s_%s%s", strings.TrimSuffix(archivedPath, ext), time.Now().Format("20060102150405"), ext)
+	}
+
+	target := r.absPath(archivedPath)
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return "", fmt.Errorf("erro ao criar diretório de arquivo: %w", err)
+	}
+
+	if err := os.Rename(r.absPath(path), target); err != nil {
+		return "", fmt.Errorf("erro ao arquivar %s: %w", path, err)
+	}
+
+	r.logger.Debug("Arquivo movido para o arquivo morto", "origem", path, "destino", archivedPath)
+	return archivedPath, nil
+}
+
+func (r *FileSystemRepository) AppendActionLog(action domain.LibraryAction) error {
+	if action.Time.IsZero() {
+		action.Time = time.Now()
+	}
+
+	data, err := json.Marshal(action)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := os.MkdirAll(r.outputDirectory, 0755); err != nil {
+		return fmt.Errorf("erro ao criar diretório: %w", err)
+	}
+
+	file, err := os.OpenFile(r.absPath(ActionLogFilename), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("erro ao abrir log de ações: %w", err)
+	}
+	defer file.Close()
+
+	_, err = file.Write(append(data, '\n'))
+	return err
+}
+
+func (r *FileSystemRepository) ReadManifest() (*domain.Manifest, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	manifest, err := r.loadManifest()
+	if err != nil {
+		return nil, err
+	}
+
+	return manifest.Clone(), nil
+}
+
+func (r *FileSystemRepository) UpdateManifest(update func(manifest *domain.Manifest)) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	manifest, err := r.loadManifest()
+	if err != nil {
+		return err
+	}
+
+	update(manifest)
+	return r.writeManifest(manifest)
+}
+
+func (r *FileSystemRepository) loadManifest() (*domain.Manifest, error) {
+	if r.manifest != nil {
+		return r.manifest, nil
+	}
+
+	data, err := os.ReadFile(r.absPath(ManifestFilename))
+	if os.IsNotExist(err) {
+		r.manifest = domain.NewManifest()
+		return r.manifest, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler manifesto: %w", err)
+	}
+
+	manifest := domain.NewManifest()
+	if err := json.Unmarshal(data, manifest); err != nil {
+		return nil, fmt.Errorf("erro ao decodificar manifesto: %w", err)
+	}
+
+	r.manifest = manifest
+	return r.manifest, nil
+}
+
+func (r *FileSystemRepository) writeManifest(manifest *domain.Manifest) error {
+	if err := os.MkdirAll(r.outputDirectory, 0755); err != nil {
+		return fmt.Errorf("erro ao criar diretório: %w", err)
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("erro ao codificar manifesto: %w", err)
+	}
+
+	manifestPath := r.absPath(ManifestFilename)
+	tempFile := manifestPath + ".tmp"
+	if err := os.WriteFile(tempFile, data, 0644); err != nil {
+		return fmt.Errorf("erro ao salvar manifesto: %w", err)
+	}
+
+	if err := os.Rename(tempFile, manifestPath); err != nil {
+		os.Remove(tempFile) // Clean up temporary file
+		return fmt.Errorf("erro ao salvar manifesto: %w", err)
+	}
+
+	return nil
+}
+
+func (r *FileSystemRepository) absPath(path string) string {
+	return filepath.Join(r.outputDirectory, filepath.FromSlash(path))
+}
@@ -0,0 +1,470 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sant0x00/downloader-music/internal/domain"
+)
+
+const (
+	defaultS3PartSize = 8 * 1024 * 1024
+	minS3PartSize     = 5 * 1024 * 1024
+)
+
+// S3Repository stores the library in an S3-compatible bucket. The manifest
+// is kept as an object next to the clips and doubles as the existence index.
+type S3Repository struct {
+	client   *s3Client
+	prefix   string
+	partSize int64
+	logger   domain.Logger
+	mu       sync.Mutex
+	manifest *domain.Manifest
+}
+
+func NewS3Repository(options S3Options, logger domain.Logger) (*S3Repository, error) {
+	client, err := newS3Client(options)
+	if err != nil {
+		return nil, err
+	}
+
+	partSize := options.PartSize
+	if partSize <= 0 {
+		partSize = defaultS3PartSize
+	}
+	if partSize < minS3PartSize {
+		partSize = minS3PartSize
+	}
+
+	return &S3Repository{
+		client:   client,
+		prefix:   strings.Trim(options.Prefix, "/"),
+		partSize: partSize,
+		logger:   logger,
+	}, nil
+}
+
+func (r *S3Repository) FindAll() ([]domain.ClipeMusical, error) {
+	manifest, err := r.ReadManifest()
+	if err != nil {
+		return nil, err
+	}
+
+	var clipes []domain.ClipeMusical
+	for _, entry := range manifest.SortedEntries() {
+		clipes = append(clipes, entry.ToClipe())
+	}
+
+	return clipes, nil
+}
+
+func (r *S3Repository) Save(clipe domain.ClipeMusical) error {
+	r.logger.Debug("Salvando clipe", "titulo", clipe.Titulo, "arquivo", clipe.GetSanitizedFilename())
+
+	return r.UpdateManifest(func(manifest *domain.Manifest) {
+		manifest.Put(domain.ManifestEntry{
+			Path:           clipe.GetRelativePath(),
+			ID:             clipe.ID,
+			DocID:          clipe.DocID,
+			Titulo:         clipe.Titulo,
+			URL:            clipe.URL,
+			URLDownload:    clipe.URLDownload,
+			Ano:            clipe.Ano,
+			Album:          clipe.Album,
+			Faixa:          clipe.Faixa,
+			Imagens:        clipe.Imagens,
+			Size:           clipe.TamanhoArquivo,
+			SHA256:         clipe.SHA256,
+			Tagged:         clipe.Tagged,
+			Duracao:        clipe.Duracao,
+			Bitrate:        clipe.Bitrate,
+			Idioma:         clipe.Idioma,
+			Chave:          clipe.Chave,
+			Publicacao:     clipe.Publicacao,
+			DataPublicacao: domain.OptionalTime(clipe.DataPublicacao),
+			Categoria:      clipe.Categoria,
+			Descricao:      clipe.Descricao,
+			Relacionados:   clipe.Relacionados,
+			DownloadedAt:   time.Now(),
+		})
+	})
+}
+
+func (r *S3Repository) Exists(relPath string) bool {
+	manifest, err := r.ReadManifest()
+	if err != nil {
+		r.logger.Warn("Não foi possível ler o manifesto", "erro", err.Error())
+		return false
+	}
+
+	candidate := ""
+	if _, exists := manifest.Get(relPath); exists {
+		candidate = relPath
+	} else if canonical, isAlias := manifest.ResolveAlias(relPath); isAlias {
+		candidate = canonical
+	}
+	if candidate == "" {
+		return false
+	}
+
+	exists, err := r.client.headObject(r.key(candidate))
+	if err != nil {
+		r.logger.Warn("Erro ao verificar objeto no S3", "arquivo", candidate, "erro", err.Error())
+		return false
+	}
+
+	if exists {
+		r.logger.Debug("Arquivo já existe", "key", r.key(candidate))
+	}
+	return exists
+}
+
+func (r *S3Repository) GetOutputDirectory() string {
+	return "s3://" + r.client.bucket + "/" + r.prefix
+}
+
+func (r *S3Repository) CreateDirectoryStructure(clipe domain.ClipeMusical) error {
+	// Object storage has no directories; keys are created on upload.
+	return nil
+}
+
+func (r *S3Repository) OpenWriter(clipe domain.ClipeMusical) (domain.ClipeWriter, error) {
+	return r.CreateFile(clipe.GetRelativePath())
+}
+
+func (r *S3Repository) CreateFile(filePath string) (domain.ClipeWriter, error) {
+	contentType := mime.TypeByExtension(path.Ext(filePath))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	return &s3ClipeWriter{
+		repository:  r,
+		key:         r.key(filePath),
+		contentType: contentType,
+		buffer:      bytes.NewBuffer(make([]byte, 0, r.partSize)),
+	}, nil
+}
+
+func (r *S3Repository) ListFiles() ([]domain.LibraryFile, error) {
+	objects, err := r.client.listObjects(r.keyPrefix())
+	if err != nil {
+		return nil, fmt.Errorf("erro ao listar biblioteca: %w", err)
+	}
+
+	var files []domain.LibraryFile
+	for _, object := range objects {
+		relPath := strings.TrimPrefix(object.Key, r.keyPrefix())
+		if relPath == "" || strings.HasPrefix(relPath, ArchiveDirectory+"/") {
+			continue
+		}
+		if strings.HasPrefix(path.Base(relPath), ".") || !audioExtensions[strings.ToLower(path.Ext(relPath))] {
+			continue
+		}
+
+		files = append(files, domain.LibraryFile{
+			Path:    relPath,
+			Size:    object.Size,
+			ModTime: object.LastModified,
+		})
+	}
+
+	return files, nil
+}
+
+func (r *S3Repository) HashFile(filePath string) (string, error) {
+	reader, err := r.OpenFile(filePath)
+	if err != nil {
+		return "", err
+	}
+	defer reader.Close()
+
+	hash := domain.NewPayloadHash()
+	if _, err := io.Copy(hash, reader); err != nil {
+		return "", fmt.Errorf("erro ao calcular hash de %s: %w", filePath, err)
+	}
+
+	return hash.Sum(), nil
+}
+
+func (r *S3Repository) OpenFile(filePath string) (io.ReadCloser, error) {
+	body, err := r.client.getObject(r.key(filePath))
+	if err != nil {
+		return nil, fmt.Errorf("erro ao abrir arquivo: %w", err)
+	}
+	if body == nil {
+		return nil, fmt.Errorf("erro ao abrir arquivo: %s não existe", filePath)
+	}
+	return body, nil
+}
+
+func (r *S3Repository) FileSize(filePath string) (int64, error) {
+	size, err := r.client.objectSize(r.key(filePath))
+	if err != nil {
+		return 0, fmt.Errorf("erro ao acessar arquivo: %w", err)
+	}
+	return size, nil
+}
+
+func (r *S3Repository) FindSidecars(filePath string) ([]string, error) {
+	stem := strings.TrimSuffix(filePath, path.Ext(filePath))
+	objects, err := r.client.listObjects(r.key(stem) + ".")
+	if err != nil {
+		return nil, fmt.Errorf("erro ao listar arquivos auxiliares: %w", err)
+	}
+
+	var sidecars []string
+	for _, object := range objects {
+		relPath := strings.TrimPrefix(object.Key, r.keyPrefix())
+		if relPath == filePath || audioExtensions[strings.ToLower(path.Ext(relPath))] {
+			continue
+		}
+		if strings.TrimSuffix(relPath, path.Ext(relPath)) == stem {
+			sidecars = append(sidecars, relPath)
+		}
+	}
+
+	return sidecars, nil
+}
+
+func (r *S3Repository) FileExists(filePath string) bool {
+	exists, err := r.client.headObject(r.key(filePath))
+	if err != nil {
+		r.logger.Warn("Erro ao verificar objeto no S3", "arquivo", filePath, "erro", err.Error())
+	}
+	return exists
+}
+
+func (r *S3Repository) LinkDuplicate(canonical, duplicate string) error {
+	return fmt.Errorf("hardlinks não são suportados no backend S3, use a política remove")
+}
+
+func (r *S3Repository) RemoveFile(filePath string) error {
+	if err := r.client.deleteObject(r.key(filePath)); err != nil {
+		return fmt.Errorf("erro ao remover arquivo: %w", err)
+	}
+
+	r.logger.Debug("Arquivo removido", "path", filePath)
+	return nil
+}
+
+func (r *S3Repository) ArchiveFile(filePath string) (string, error) {
+	archivedPath := path.Join(ArchiveDirectory, filePath)
+	if exists, _ := r.client.headObject(r.key(archivedPath)); exists {
+		ext := path.Ext(archivedPath)
+		archivedPath = fmt.Sprintf("%s_%s%s", strings.TrimSuffix(archivedPath, ext), time.Now().Format("20060102150405"), ext)
+	}
+
+	if err := r.client.copyObject(r.key(filePath), r.key(archivedPath)); err != nil {
+		return "", fmt.Errorf("erro ao arquivar %s: %w", filePath, err)
+	}
+	if err := r.client.deleteObject(r.key(filePath)); err != nil {
+		return "", fmt.Errorf("erro ao arquivar %s: %w", filePath, err)
+	}
+
+	r.logger.Debug("Arquivo movido para o arquivo morto", "origem", filePath, "destino", archivedPath)
+	return archivedPath, nil
+}
+
+func (r *S3Repository) AppendActionLog(action domain.LibraryAction) error {
+	if action.Time.IsZero() {
+		action.Time = time.Now()
+	}
+
+	data, err := json.Marshal(action)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// Objects cannot be appended to, so rewrite the whole log.
+	var existing []byte
+	body, err := r.client.getObject(r.key(ActionLogFilename))
+	if err != nil {
+		return fmt.Errorf("erro ao ler log de ações: %w", err)
+	}
+	if body != nil {
+		existing, err = io.ReadAll(body)
+		body.Close()
+		if err != nil {
+			return fmt.Errorf("erro ao ler log de ações: %w", err)
+		}
+	}
+
+	existing = append(existing, data...)
+	existing = append(existing, '\n')
+	return r.client.putObject(r.key(ActionLogFilename), existing, "application/x-ndjson")
+}
+
+func (r *S3Repository) ReadManifest() (*domain.Manifest, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	manifest, err := r.loadManifest()
+	if err != nil {
+		return nil, err
+	}
+
+	return manifest.Clone(), nil
+}
+
+func (r *S3Repository) UpdateManifest(update func(manifest *domain.Manifest)) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	manifest, err := r.loadManifest()
+	if err != nil {
+		return err
+	}
+
+	update(manifest)
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("erro ao codificar manifesto: %w", err)
+	}
+
+	if err := r.client.putObject(r.key(ManifestFilename), data, "application/json"); err != nil {
+		return fmt.Errorf("erro ao salvar manifesto: %w", err)
+	}
+	return nil
+}
+
+func (r *S3Repository) loadManifest() (*domain.Manifest, error) {
+	if r.manifest != nil {
+		return r.manifest, nil
+	}
+
+	body, err := r.client.getObject(r.key(ManifestFilename))
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler manifesto: %w", err)
+	}
+	if body == nil {
+		r.manifest = domain.NewManifest()
+		return r.manifest, nil
+	}
+	defer body.Close()
+
+	manifest := domain.NewManifest()
+	if err := json.NewDecoder(body).Decode(manifest); err != nil {
+		return nil, fmt.Errorf("erro ao decodificar manifesto: %w", err)
+	}
+
+	r.manifest = manifest
+	return r.manifest, nil
+}
+
+func (r *S3Repository) key(relPath string) string {
+	return r.keyPrefix() + strings.TrimPrefix(relPath, "/")
+}
+
+func (r *S3Repository) keyPrefix() string {
+	if r.prefix == "" {
+		return ""
+	}
+	return r.prefix + "/"
+}
+
+// s3ClipeWriter buffers one part at a time, so a download is streamed to the
+// bucket without ever holding the whole file in memory. Files smaller than
+// one part are sent with a single PUT.
+type s3ClipeWriter struct {
+	repository  *S3Repository
+	key         string
+	contentType string
+	buffer      *bytes.Buffer
+	uploadID    string
+	parts       []s3CompletedPart
+}
+
+func (w *s3ClipeWriter) Write(p []byte) (int, error) {
+	written := 0
+	for len(p) > 0 {
+		room := int(w.repository.partSize) - w.buffer.Len()
+		if room > len(p) {
+			room = len(p)
+		}
+
+		w.buffer.Write(p[:room])
+		written += room
+		p = p[room:]
+
+		if int64(w.buffer.Len()) >= w.repository.partSize {
+			if err := w.flushPart(); err != nil {
+				return written, err
+			}
+		}
+	}
+	return written, nil
+}
+
+func (w *s3ClipeWriter) Location() string {
+	return "s3://" + w.repository.client.bucket + "/" + w.key
+}
+
+func (w *s3ClipeWriter) Commit() error {
+	client := w.repository.client
+
+	if w.uploadID == "" {
+		if err := client.putObject(w.key, w.buffer.Bytes(), w.contentType); err != nil {
+			return fmt.Errorf("erro ao enviar arquivo: %w", err)
+		}
+		return nil
+	}
+
+	if w.buffer.Len() > 0 {
+		if err := w.flushPart(); err != nil {
+			w.Abort()
+			return err
+		}
+	}
+
+	if err := client.completeMultipartUpload(w.key, w.uploadID, w.parts); err != nil {
+		w.Abort()
+		return fmt.Errorf("erro ao finalizar envio: %w", err)
+	}
+	return nil
+}
+
+func (w *s3ClipeWriter) Abort() error {
+	w.buffer.Reset()
+	if w.uploadID == "" {
+		return nil
+	}
+
+	uploadID := w.uploadID
+	w.uploadID = ""
+	return w.repository.client.abortMultipartUpload(w.key, uploadID)
+}
+
+func (w *s3ClipeWriter) flushPart() error {
+	client := w.repository.client
+
+	if w.uploadID == "" {
+		uploadID, err := client.createMultipartUpload(w.key, w.contentType)
+		if err != nil {
+			return fmt.Errorf("erro ao iniciar envio multipart: %w", err)
+		}
+		w.uploadID = uploadID
+	}
+
+	partNumber := len(w.parts) + 1
+	etag, err := client.uploadPart(w.key, w.uploadID, partNumber, w.buffer.Bytes())
+	if err != nil {
+		return fmt.Errorf("erro ao enviar parte %d: %w", partNumber, err)
+	}
+
+	w.parts = append(w.parts, s3CompletedPart{PartNumber: partNumber, ETag: etag})
+	w.buffer.Reset()
+	return nil
+}
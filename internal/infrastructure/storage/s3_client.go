@@ -0,0 +1,492 @@
+package storage
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const emptyPayloadHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+type S3Options struct {
+	Endpoint        string
+	Region          string
+	Bucket          string
+	Prefix          string
+	AccessKeyID     string
+	SecretAccessKey string
+	UsePathStyle    bool
+	PartSize        int64
+	Timeout         time.Duration
+}
+
+// s3Client speaks the subset of the S3 REST API used by the repository,
+// signing every request with AWS Signature Version 4.
+type s3Client struct {
+	endpoint        *url.URL
+	region          string
+	bucket          string
+	accessKeyID     string
+	secretAccessKey string
+	usePathStyle    bool
+	client          *http.Client
+}
+
+type s3Object struct {
+	Key          string    `xml:"Key"`
+	Size         int64     `xml:"Size"`
+	LastModified time.Time `xml:"LastModified"`
+	ETag         string    `xml:"ETag"`
+}
+
+type s3ListResult struct {
+	Contents              []s3Object `xml:"Contents"`
+	IsTruncated           bool       `xml:"IsTruncated"`
+	NextContinuationToken string     `xml:"NextContinuationToken"`
+}
+
+type s3InitiateMultipartResult struct {
+	UploadID string `xml:"UploadId"`
+}
+
+type s3CompletedPart struct {
+	PartNumber int    `xml:"PartNumber"`
+	ETag       string `xml:"ETag"`
+}
+
+type s3CompleteMultipartUpload struct {
+	XMLName xml.Name          `xml:"CompleteMultipartUpload"`
+	Parts   []s3CompletedPart `xml:"Part"`
+}
+
+type s3Error struct {
+	Code    string `xml:"Code"`
+	Message string `xml:"Message"`
+}
+
+func newS3Client(options S3Options) (*s3Client, error) {
+	if options.Endpoint == "" {
+		return nil, fmt.Errorf("endpoint S3 não configurado")
+	}
+	if options.Bucket == "" {
+		return nil, fmt.Errorf("bucket S3 não configurado")
+	}
+
+	endpoint, err := url.Parse(options.Endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("endpoint S3 inválido: %w", err)
+	}
+	if endpoint.Scheme == "" || endpoint.Host == "" {
+		return nil, fmt.Errorf("endpoint S3 inválido: %s", options.Endpoint)
+	}
+
+	region := options.Region
+	if region == "" {
+		region = "us-east-1"
+	}
+
+	return &s3Client{
+		endpoint:        endpoint,
+		region:          region,
+		bucket:          options.Bucket,
+		accessKeyID:     options.AccessKeyID,
+		secretAccessKey: options.SecretAccessKey,
+		usePathStyle:    options.UsePathStyle,
+		client:          &http.Client{Timeout: options.Timeout},
+	}, nil
+}
+
+func (c *s3Client) headObject(key string) (bool, error) {
+	resp, err := c.do("HEAD", key, nil, nil, nil)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return true, nil
+	case http.StatusNotFound:
+		return false, nil
+	default:
+		return false, fmt.Errorf("HEAD %s: status code inválido: %d", key, resp.StatusCode)
+	}
+}
+
+func (c *s3Client) objectSize(key string) (int64, error) {
+	resp, err := c.do("HEAD", key, nil, nil, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("HEAD %s: status code inválido: %d", key, resp.StatusCode)
+	}
+	return resp.ContentLength, nil
+}
+
+// getObject returns the object body; callers must close it. A missing
+// object is reported as (nil, nil).
+func (c *s3Client) getObject(key string) (io.ReadCloser, error) {
+	resp, err := c.do("GET", key, nil, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusNotFound {
+		resp.Body.Close()
+		return nil, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		return nil, c.responseError("GET", key, resp)
+	}
+
+	return resp.Body, nil
+}
+
+func (c *s3Client) putObject(key string, body []byte, contentType string) error {
+	headers := map[string]string{}
+	if contentType != "" {
+		headers["Content-Type"] = contentType
+	}
+
+	resp, err := c.do("PUT", key, nil, headers, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return c.responseError("PUT", key, resp)
+	}
+	return nil
+}
+
+func (c *s3Client) deleteObject(key string) error {
+	resp, err := c.do("DELETE", key, nil, nil, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK {
+		return c.responseError("DELETE", key, resp)
+	}
+	return nil
+}
+
+func (c *s3Client) copyObject(sourceKey, targetKey string) error {
+	headers := map[string]string{
+		"X-Amz-Copy-Source": "/" + c.bucket + "/" + encodeS3Path(sourceKey),
+	}
+
+	resp, err := c.do("PUT", targetKey, nil, headers, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return c.responseError("COPY", targetKey, resp)
+	}
+	return nil
+}
+
+func (c *s3Client) listObjects(prefix string) ([]s3Object, error) {
+	var objects []s3Object
+	token := ""
+
+	for {
+		query := url.Values{}
+		query.Set("list-type", "2")
+		query.Set("prefix", prefix)
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+
+		resp, err := c.do("GET", "", query, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			err := c.responseError("LIST", prefix, resp)
+			resp.Body.Close()
+			return nil, err
+		}
+
+		var result s3ListResult
+		err = xml.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("erro ao decodificar listagem S3: %w", err)
+		}
+
+		objects = append(objects, result.Contents...)
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			return objects, nil
+		}
+		token = result.NextContinuationToken
+	}
+}
+
+func (c *s3Client) createMultipartUpload(key, contentType string) (string, error) {
+	query := url.Values{}
+	query.Set("uploads", "")
+
+	headers := map[string]string{}
+	if contentType != "" {
+		headers["Content-Type"] = contentType
+	}
+
+	resp, err := c.do("POST", key, query, headers, nil)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", c.responseError("CreateMultipartUpload", key, resp)
+	}
+
+	var result s3InitiateMultipartResult
+	if err := xml.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("erro ao decodificar resposta S3: %w", err)
+	}
+	if result.UploadID == "" {
+		return "", fmt.Errorf("S3 não retornou UploadId para %s", key)
+	}
+
+	return result.UploadID, nil
+}
+
+func (c *s3Client) uploadPart(key, uploadID string, partNumber int, body []byte) (string, error) {
+	query := url.Values{}
+	query.Set("partNumber", strconv.Itoa(partNumber))
+	query.Set("uploadId", uploadID)
+
+	resp, err := c.do("PUT", key, query, nil, body)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", c.responseError("UploadPart", key, resp)
+	}
+
+	return resp.Header.Get("ETag"), nil
+}
+
+func (c *s3Client) completeMultipartUpload(key, uploadID string, parts []s3CompletedPart) error {
+	body, err := xml.Marshal(s3CompleteMultipartUpload{Parts: parts})
+	if err != nil {
+		return err
+	}
+
+	query := url.Values{}
+	query.Set("uploadId", uploadID)
+
+	resp, err := c.do("POST", key, query, map[string]string{"Content-Type": "application/xml"}, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	// S3 may report a failure inside a 200 response, so always inspect the body.
+	data, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK || bytes.Contains(data, []byte("<Error>")) {
+		return fmt.Errorf("CompleteMultipartUpload %s falhou: status %d: %s", key, resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+	return nil
+}
+
+func (c *s3Client) abortMultipartUpload(key, uploadID string) error {
+	query := url.Values{}
+	query.Set("uploadId", uploadID)
+
+	resp, err := c.do("DELETE", key, query, nil, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK {
+		return c.responseError("AbortMultipartUpload", key, resp)
+	}
+	return nil
+}
+
+func (c *s3Client) do(method, key string, query url.Values, headers map[string]string, body []byte) (*http.Response, error) {
+	requestURL := c.objectURL(key, query)
+
+	var bodyReader io.Reader
+	if body != nil {
+		bodyReader = bytes.NewReader(body)
+	}
+
+	req, err := http.NewRequest(method, requestURL.String(), bodyReader)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao criar requisição S3: %w", err)
+	}
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+
+	c.sign(req, body, time.Now().UTC())
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("erro na requisição S3: %w", err)
+	}
+	return resp, nil
+}
+
+func (c *s3Client) objectURL(key string, query url.Values) *url.URL {
+	u := *c.endpoint
+	basePath := strings.TrimSuffix(u.Path, "/")
+
+	if c.usePathStyle {
+		u.Path = basePath + "/" + c.bucket
+	} else {
+		u.Host = c.bucket + "." + u.Host
+		u.Path = basePath
+	}
+	if key != "" {
+		u.Path += "/" + key
+	}
+	if u.Path == "" {
+		u.Path = "/"
+	}
+
+	u.RawPath = encodeS3Path(u.Path)
+	u.RawQuery = encodeS3Query(query)
+	return &u
+}
+
+func (c *s3Client) sign(req *http.Request, body []byte, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+
+	payloadHash := emptyPayloadHash
+	if len(body) > 0 {
+		sum := sha256.Sum256(body)
+		payloadHash = hex.EncodeToString(sum[:])
+	}
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	var headerNames []string
+	canonicalHeaders := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		lower := strings.ToLower(name)
+		if lower == "content-type" || strings.HasPrefix(lower, "x-amz-") {
+			canonicalHeaders[lower] = strings.TrimSpace(strings.Join(values, ","))
+		}
+	}
+	for name := range canonicalHeaders {
+		headerNames = append(headerNames, name)
+	}
+	sort.Strings(headerNames)
+
+	var headerBlock strings.Builder
+	for _, name := range headerNames {
+		headerBlock.WriteString(name + ":" + canonicalHeaders[name] + "\n")
+	}
+	signedHeaders := strings.Join(headerNames, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		headerBlock.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + c.region + "/s3/aws4_request"
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(requestHash[:])
+
+	signingKey := hmacSHA256([]byte("AWS4"+c.secretAccessKey), date)
+	signingKey = hmacSHA256(signingKey, c.region)
+	signingKey = hmacSHA256(signingKey, "s3")
+	signingKey = hmacSHA256(signingKey, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(signingKey, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf(
+		"AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		c.accessKeyID, scope, signedHeaders, signature,
+	))
+}
+
+func (c *s3Client) responseError(operation, key string, resp *http.Response) error {
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+
+	var apiErr s3Error
+	if xml.Unmarshal(data, &apiErr) == nil && apiErr.Code != "" {
+		return fmt.Errorf("%s %s: %s: %s", operation, key, apiErr.Code, apiErr.Message)
+	}
+	return fmt.Errorf("%s %s: status code inválido: %d", operation, key, resp.StatusCode)
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// encodeS3Path escapes each path segment following the SigV4 rules.
+func encodeS3Path(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		segments[i] = encodeS3Component(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+func encodeS3Query(query url.Values) string {
+	if len(query) == 0 {
+		return ""
+	}
+
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var parts []string
+	for _, key := range keys {
+		for _, value := range query[key] {
+			parts = append(parts, encodeS3Component(key)+"="+encodeS3Component(value))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+func encodeS3Component(value string) string {
+	var b strings.Builder
+	for _, c := range []byte(value) {
+		if (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') ||
+			c == '-' || c == '_' || c == '.' || c == '~' {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,560 @@
+package storage
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const (
+	testBucket    = "biblioteca"
+	testAccessKey = "AKIDEXAMPLE"
+	testSecretKey = "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY"
+	testRegion    = "sa-east-1"
+	testPageSize  = 2
+)
+
+// fakeS3 is a path-style S3 endpoint keeping objects in memory. Every request
+// must carry a valid SigV4 signature, recomputed here from what was received.
+type fakeS3 struct {
+	mu       sync.Mutex
+	objects  map[string][]byte
+	uploads  map[string]map[int][]byte
+	nextID   int
+	requests []string
+	rejected []string
+}
+
+func newFakeS3(t *testing.T) (*fakeS3, *s3Client) {
+	t.Helper()
+
+	fake := &fakeS3{objects: map[string][]byte{}, uploads: map[string]map[int][]byte{}}
+	server := httptest.NewServer(fake)
+	t.Cleanup(func() {
+		server.Close()
+		for _, rejection := range fake.rejected {
+			t.Errorf("requisição rejeitada: %s", rejection)
+		}
+	})
+
+	client, err := newS3Client(S3Options{
+		Endpoint:        server.URL,
+		Region:          testRegion,
+		Bucket:          testBucket,
+		AccessKeyID:     testAccessKey,
+		SecretAccessKey: testSecretKey,
+		UsePathStyle:    true,
+	})
+	if err != nil {
+		t.Fatalf("newS3Client: %v", err)
+	}
+	return fake, client
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := verifySignature(r, body); err != nil {
+		f.mu.Lock()
+		f.rejected = append(f.rejected, fmt.Sprintf("%s %s: %v", r.Method, r.URL, err))
+		f.mu.Unlock()
+		writeS3Error(w, http.StatusForbidden, "SignatureDoesNotMatch", err.Error())
+		return
+	}
+
+	bucketPrefix := "/" + testBucket
+	if r.URL.Path != bucketPrefix && !strings.HasPrefix(r.URL.Path, bucketPrefix+"/") {
+		writeS3Error(w, http.StatusNotFound, "NoSuchBucket", r.URL.Path)
+		return
+	}
+	key := strings.TrimPrefix(strings.TrimPrefix(r.URL.Path, bucketPrefix), "/")
+	query := r.URL.Query()
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	operation := r.Method
+	switch {
+	case r.Method == http.MethodGet && key == "" && query.Get("list-type") == "2":
+		operation = "LIST"
+		f.list(w, query)
+	case r.Method == http.MethodPost && query.Has("uploads"):
+		operation = "INITIATE"
+		f.nextID++
+		uploadID := fmt.Sprintf("upload-%d", f.nextID)
+		f.uploads[uploadID] = map[int][]byte{}
+		fmt.Fprintf(w, "<InitiateMultipartUploadResult><Key>%s</Key><UploadId>%s</UploadId></InitiateMultipartUploadResult>", key, uploadID)
+	case r.Method == http.MethodPut && query.Has("partNumber"):
+		operation = "PART"
+		parts, ok := f.uploads[query.Get("uploadId")]
+		if !ok {
+			writeS3Error(w, http.StatusNotFound, "NoSuchUpload", query.Get("uploadId"))
+			break
+		}
+		number, _ := strconv.Atoi(query.Get("partNumber"))
+		parts[number] = body
+		w.Header().Set("ETag", etag(body))
+	case r.Method == http.MethodPost && query.Has("uploadId"):
+		operation = "COMPLETE"
+		f.complete(w, key, query.Get("uploadId"), body)
+	case r.Method == http.MethodDelete && query.Has("uploadId"):
+		operation = "ABORT"
+		delete(f.uploads, query.Get("uploadId"))
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == http.MethodPut && r.Header.Get("X-Amz-Copy-Source") != "":
+		operation = "COPY"
+		source, err := url.PathUnescape(r.Header.Get("X-Amz-Copy-Source"))
+		if err != nil {
+			writeS3Error(w, http.StatusBadRequest, "InvalidArgument", err.Error())
+			break
+		}
+		data, ok := f.objects[strings.TrimPrefix(source, bucketPrefix+"/")]
+		if !ok {
+			writeS3Error(w, http.StatusNotFound, "NoSuchKey", source)
+			break
+		}
+		f.objects[key] = data
+		fmt.Fprintf(w, "<CopyObjectResult><ETag>%s</ETag></CopyObjectResult>", etag(data))
+	case r.Method == http.MethodPut:
+		f.objects[key] = body
+		w.Header().Set("ETag", etag(body))
+	case r.Method == http.MethodHead:
+		if data, ok := f.objects[key]; ok {
+			w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+			break
+		}
+		w.WriteHeader(http.StatusNotFound)
+	case r.Method == http.MethodGet:
+		data, ok := f.objects[key]
+		if !ok {
+			writeS3Error(w, http.StatusNotFound, "NoSuchKey", key)
+			break
+		}
+		w.Write(data)
+	case r.Method == http.MethodDelete:
+		delete(f.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeS3Error(w, http.StatusNotImplemented, "NotImplemented", r.Method)
+	}
+	f.requests = append(f.requests, operation)
+}
+
+// list answers ListObjectsV2 a few keys at a time, so callers must follow
+// the continuation token.
+func (f *fakeS3) list(w http.ResponseWriter, query url.Values) {
+	var keys []string
+	for key := range f.objects {
+		if strings.HasPrefix(key, query.Get("prefix")) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	start := 0
+	if token := query.Get("continuation-token"); token != "" {
+		start, _ = strconv.Atoi(strings.TrimPrefix(token, "token-"))
+	}
+	end := start + testPageSize
+	if end > len(keys) {
+		end = len(keys)
+	}
+
+	var b strings.Builder
+	b.WriteString("<ListBucketResult>")
+	for _, key := range keys[start:end] {
+		b.WriteString("<Contents><Key>")
+		xml.EscapeText(&b, []byte(key))
+		fmt.Fprintf(&b, "</Key><Size>%d</Size><LastModified>2024-05-01T10:00:00.000Z</LastModified><ETag>%s</ETag></Contents>",
+			len(f.objects[key]), etag(f.objects[key]))
+	}
+	if end < len(keys) {
+		fmt.Fprintf(&b, "<IsTruncated>true</IsTruncated><NextContinuationToken>token-%d</NextContinuationToken>", end)
+	} else {
+		b.WriteString("<IsTruncated>false</IsTruncated>")
+	}
+	b.WriteString("</ListBucketResult>")
+	io.WriteString(w, b.String())
+}
+
+func (f *fakeS3) complete(w http.ResponseWriter, key, uploadID string, body []byte) {
+	parts, ok := f.uploads[uploadID]
+	if !ok {
+		writeS3Error(w, http.StatusNotFound, "NoSuchUpload", uploadID)
+		return
+	}
+
+	var request s3CompleteMultipartUpload
+	if err := xml.Unmarshal(body, &request); err != nil || len(request.Parts) == 0 {
+		writeS3Error(w, http.StatusBadRequest, "MalformedXML", string(body))
+		return
+	}
+
+	var data []byte
+	for i, part := range request.Parts {
+		content, ok := parts[part.PartNumber]
+		if part.PartNumber != i+1 || !ok || part.ETag != etag(content) {
+			// Real S3 reports this inside a 200 response.
+			fmt.Fprintf(w, "<Error><Code>InvalidPart</Code><Message>parte %d</Message></Error>", part.PartNumber)
+			return
+		}
+		data = append(data, content...)
+	}
+
+	f.objects[key] = data
+	delete(f.uploads, uploadID)
+	fmt.Fprintf(w, "<CompleteMultipartUploadResult><Key>%s</Key></CompleteMultipartUploadResult>", key)
+}
+
+func (f *fakeS3) operations() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.requests...)
+}
+
+func writeS3Error(w http.ResponseWriter, status int, code, message string) {
+	w.WriteHeader(status)
+	fmt.Fprintf(w, "<Error><Code>%s</Code><Message>%s</Message></Error>", code, message)
+}
+
+func etag(data []byte) string {
+	sum := sha256.Sum256(data)
+	return `"` + hex.EncodeToString(sum[:8]) + `"`
+}
+
+// verifySignature checks the SigV4 headers the way S3 does: the payload hash
+// must match the body and the signature must match the canonical request
+// rebuilt from the received method, path, query and signed headers.
+func verifySignature(r *http.Request, body []byte) error {
+	amzDate := r.Header.Get("X-Amz-Date")
+	signedAt, err := time.Parse("20060102T150405Z", amzDate)
+	if err != nil {
+		return fmt.Errorf("X-Amz-Date inválido: %q", amzDate)
+	}
+	if skew := time.Since(signedAt); skew > 15*time.Minute || skew < -15*time.Minute {
+		return fmt.Errorf("X-Amz-Date fora da janela: %s", amzDate)
+	}
+
+	sum := sha256.Sum256(body)
+	payloadHash := hex.EncodeToString(sum[:])
+	if got := r.Header.Get("X-Amz-Content-Sha256"); got != payloadHash {
+		return fmt.Errorf("X-Amz-Content-Sha256 = %q, corpo tem %q", got, payloadHash)
+	}
+
+	fields := map[string]string{}
+	authorization, ok := strings.CutPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 ")
+	if !ok {
+		return fmt.Errorf("Authorization sem AWS4-HMAC-SHA256: %q", r.Header.Get("Authorization"))
+	}
+	for _, field := range strings.Split(authorization, ", ") {
+		name, value, _ := strings.Cut(field, "=")
+		fields[name] = value
+	}
+
+	date := signedAt.Format("20060102")
+	scope := date + "/" + testRegion + "/s3/aws4_request"
+	if want := testAccessKey + "/" + scope; fields["Credential"] != want {
+		return fmt.Errorf("Credential = %q, want %q", fields["Credential"], want)
+	}
+
+	signedHeaders := strings.Split(fields["SignedHeaders"], ";")
+	for _, required := range []string{"host", "x-amz-content-sha256", "x-amz-date"} {
+		if !containsString(signedHeaders, required) {
+			return fmt.Errorf("cabeçalho %s não assinado", required)
+		}
+	}
+	if r.Header.Get("X-Amz-Copy-Source") != "" && !containsString(signedHeaders, "x-amz-copy-source") {
+		return fmt.Errorf("cabeçalho x-amz-copy-source não assinado")
+	}
+
+	var headerBlock strings.Builder
+	for _, name := range signedHeaders {
+		value := r.Header.Get(name)
+		if name == "host" {
+			value = r.Host
+		}
+		headerBlock.WriteString(name + ":" + strings.TrimSpace(value) + "\n")
+	}
+
+	canonicalRequest := strings.Join([]string{
+		r.Method,
+		canonicalURI(r.URL.Path),
+		canonicalQuery(r.URL.Query()),
+		headerBlock.String(),
+		fields["SignedHeaders"],
+		payloadHash,
+	}, "\n")
+
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(requestHash[:])
+
+	key := []byte("AWS4" + testSecretKey)
+	for _, part := range []string{date, testRegion, "s3", "aws4_request"} {
+		key = testHMAC(key, part)
+	}
+	if want := hex.EncodeToString(testHMAC(key, stringToSign)); fields["Signature"] != want {
+		return fmt.Errorf("Signature = %q, want %q\n%s", fields["Signature"], want, canonicalRequest)
+	}
+	return nil
+}
+
+func canonicalURI(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		segments[i] = uriEncode(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+func canonicalQuery(query url.Values) string {
+	var pairs []string
+	for name, values := range query {
+		for _, value := range values {
+			pairs = append(pairs, uriEncode(name)+"="+uriEncode(value))
+		}
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, "&")
+}
+
+// uriEncode escapes everything but the unreserved characters of RFC 3986.
+func uriEncode(value string) string {
+	escaped := url.QueryEscape(value)
+	escaped = strings.ReplaceAll(escaped, "+", "%20")
+	escaped = strings.ReplaceAll(escaped, "%7E", "~")
+	return escaped
+}
+
+func testHMAC(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func TestS3ClientPutGetHead(t *testing.T) {
+	fake, client := newFakeS3(t)
+	key := "clipes/2024/Canção nova (ao vivo)+1.mp3"
+
+	if err := client.putObject(key, []byte("áudio"), "audio/mpeg"); err != nil {
+		t.Fatalf("putObject: %v", err)
+	}
+	if string(fake.objects[key]) != "áudio" {
+		t.Fatalf("object = %q", fake.objects[key])
+	}
+
+	exists, err := client.headObject(key)
+	if err != nil || !exists {
+		t.Fatalf("headObject(%q) = %v, %v; want true", key, exists, err)
+	}
+	if size, err := client.objectSize(key); err != nil || size != int64(len("áudio")) {
+		t.Fatalf("objectSize(%q) = %d, %v", key, size, err)
+	}
+	exists, err = client.headObject("clipes/2024/ausente.mp3")
+	if err != nil || exists {
+		t.Fatalf("headObject(ausente) = %v, %v; want false", exists, err)
+	}
+
+	reader, err := client.getObject(key)
+	if err != nil {
+		t.Fatalf("getObject: %v", err)
+	}
+	defer reader.Close()
+	if data, _ := io.ReadAll(reader); string(data) != "áudio" {
+		t.Errorf("getObject = %q", data)
+	}
+
+	// An empty body is signed with the hash of nothing.
+	if err := client.putObject("vazio.txt", nil, "text/plain"); err != nil {
+		t.Fatalf("putObject vazio: %v", err)
+	}
+}
+
+func TestS3ClientCopy(t *testing.T) {
+	fake, client := newFakeS3(t)
+	fake.objects["2023/Louvor à noite.mp3"] = []byte("dados")
+
+	if err := client.copyObject("2023/Louvor à noite.mp3", ".arquivados/2023/Louvor à noite.mp3"); err != nil {
+		t.Fatalf("copyObject: %v", err)
+	}
+	if string(fake.objects[".arquivados/2023/Louvor à noite.mp3"]) != "dados" {
+		t.Errorf("copy missing: %v", fake.objects)
+	}
+
+	if err := client.copyObject("ausente.mp3", "destino.mp3"); err == nil || !strings.Contains(err.Error(), "NoSuchKey") {
+		t.Errorf("copyObject(ausente) error = %v, want NoSuchKey", err)
+	}
+}
+
+func TestS3ClientListPaginates(t *testing.T) {
+	fake, client := newFakeS3(t)
+	want := []string{"lib/2022/a.mp3", "lib/2023/b c.mp3", "lib/2024/d.lrc", "lib/2024/ç.mp3", "lib/outros/e.mp3"}
+	for _, key := range want {
+		fake.objects[key] = []byte(key)
+	}
+	fake.objects["outro/f.mp3"] = []byte("fora do prefixo")
+
+	objects, err := client.listObjects("lib/")
+	if err != nil {
+		t.Fatalf("listObjects: %v", err)
+	}
+
+	var got []string
+	for _, object := range objects {
+		got = append(got, object.Key)
+		if object.Size != int64(len(object.Key)) {
+			t.Errorf("%s: size = %d", object.Key, object.Size)
+		}
+	}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("keys = %v, want %v", got, want)
+	}
+
+	pages := 0
+	for _, operation := range fake.operations() {
+		if operation == "LIST" {
+			pages++
+		}
+	}
+	if pages != 3 {
+		t.Errorf("listed %d pages, want 3", pages)
+	}
+}
+
+func TestS3ClientMultipart(t *testing.T) {
+	fake, client := newFakeS3(t)
+	key := "2024/Grande clipe.mp4"
+
+	uploadID, err := client.createMultipartUpload(key, "video/mp4")
+	if err != nil {
+		t.Fatalf("createMultipartUpload: %v", err)
+	}
+
+	var parts []s3CompletedPart
+	for i, chunk := range []string{"primeira-", "segunda-", "terceira"} {
+		tag, err := client.uploadPart(key, uploadID, i+1, []byte(chunk))
+		if err != nil {
+			t.Fatalf("uploadPart %d: %v", i+1, err)
+		}
+		parts = append(parts, s3CompletedPart{PartNumber: i + 1, ETag: tag})
+	}
+
+	if err := client.completeMultipartUpload(key, uploadID, parts); err != nil {
+		t.Fatalf("completeMultipartUpload: %v", err)
+	}
+	if got := string(fake.objects[key]); got != "primeira-segunda-terceira" {
+		t.Errorf("object = %q", got)
+	}
+}
+
+func TestS3ClientMultipartErrorInsideOK(t *testing.T) {
+	_, client := newFakeS3(t)
+	key := "2024/clipe.mp4"
+
+	uploadID, err := client.createMultipartUpload(key, "video/mp4")
+	if err != nil {
+		t.Fatalf("createMultipartUpload: %v", err)
+	}
+	if _, err := client.uploadPart(key, uploadID, 1, []byte("parte")); err != nil {
+		t.Fatalf("uploadPart: %v", err)
+	}
+
+	err = client.completeMultipartUpload(key, uploadID, []s3CompletedPart{{PartNumber: 1, ETag: `"errado"`}})
+	if err == nil || !strings.Contains(err.Error(), "InvalidPart") {
+		t.Errorf("completeMultipartUpload error = %v, want InvalidPart", err)
+	}
+}
+
+func TestS3ClientAbort(t *testing.T) {
+	fake, client := newFakeS3(t)
+	key := "2024/cancelado.mp4"
+
+	uploadID, err := client.createMultipartUpload(key, "video/mp4")
+	if err != nil {
+		t.Fatalf("createMultipartUpload: %v", err)
+	}
+	if _, err := client.uploadPart(key, uploadID, 1, []byte("parte")); err != nil {
+		t.Fatalf("uploadPart: %v", err)
+	}
+	if err := client.abortMultipartUpload(key, uploadID); err != nil {
+		t.Fatalf("abortMultipartUpload: %v", err)
+	}
+
+	if len(fake.uploads) != 0 {
+		t.Errorf("upload still open: %v", fake.uploads)
+	}
+	if _, ok := fake.objects[key]; ok {
+		t.Errorf("aborted upload created %s", key)
+	}
+}
+
+func TestS3ClientRejectsWrongSecret(t *testing.T) {
+	fake, client := newFakeS3(t)
+	client.secretAccessKey = "outra-chave"
+
+	if err := client.putObject("a.mp3", []byte("x"), "audio/mpeg"); err == nil || !strings.Contains(err.Error(), "SignatureDoesNotMatch") {
+		t.Errorf("putObject error = %v, want SignatureDoesNotMatch", err)
+	}
+	if len(fake.rejected) != 1 {
+		t.Errorf("rejected = %v, want one request", fake.rejected)
+	}
+	fake.rejected = nil
+}
+
+func TestS3WriterUploadsInParts(t *testing.T) {
+	fake, client := newFakeS3(t)
+	repository := &S3Repository{client: client, prefix: "lib", partSize: 4}
+
+	writer := &s3ClipeWriter{
+		repository:  repository,
+		key:         repository.key("2024/clipe.mp4"),
+		contentType: "video/mp4",
+		buffer:      &bytes.Buffer{},
+	}
+	if _, err := io.WriteString(writer, "0123456789"); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if err := writer.Commit(); err != nil {
+		t.Fatalf("Commit: %v", err)
+	}
+
+	if got := string(fake.objects["lib/2024/clipe.mp4"]); got != "0123456789" {
+		t.Errorf("object = %q", got)
+	}
+	if got, want := strings.Join(fake.operations(), ","), "INITIATE,PART,PART,PART,COMPLETE"; got != want {
+		t.Errorf("requests = %s, want %s", got, want)
+	}
+
+	small := &s3ClipeWriter{repository: repository, key: repository.key("2024/curto.mp3"), contentType: "audio/mpeg", buffer: &bytes.Buffer{}}
+	io.WriteString(small, "abc")
+	if err := small.Commit(); err != nil {
+		t.Fatalf("Commit: %v", err)
+	}
+	if got := string(fake.objects["lib/2024/curto.mp3"]); got != "abc" {
+		t.Errorf("small object = %q", got)
+	}
+}
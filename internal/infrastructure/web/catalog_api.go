@@ -0,0 +1,157 @@
+package web
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/sant0x00/downloader-music/internal/domain"
+)
+
+type ScrapingMode string
+
+const (
+	ScrapingModeHTML ScrapingMode = "html"
+	ScrapingModeAPI  ScrapingMode = "api"
+)
+
+func ParseScrapingMode(value string) (ScrapingMode, error) {
+	switch mode := ScrapingMode(strings.ToLower(strings.TrimSpace(value))); mode {
+	case "":
+		return ScrapingModeHTML, nil
+	case ScrapingModeHTML, ScrapingModeAPI:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("modo de scraping inválido: %s (use html ou api)", value)
+	}
+}
+
+// SetMode chooses where the catalog comes from. In API mode the listing page
+// is only read when enrich is set, to add page links and thumbnails.
+func (s *JWScraper) SetMode(mode ScrapingMode, enrich bool) {
+	s.mode = mode
+	s.enrichHTML = enrich
+}
+
+// listFromAPI builds the catalog of a language from the pub-media files
+// alone: title, track, size, duration and publication date all come from the
+// API. The year is left to the download service, as in the HTML mode.
+func (s *JWScraper) listFromAPI(language string) ([]domain.ClipeMusical, error) {
+	s.logger.Info("Obtendo lista de clipes via API", "idioma", language)
+
+	index, err := s.languageIndex(language)
+	if err != nil {
+		return nil, err
+	}
+
+	clipes := make([]domain.ClipeMusical, 0, len(index.files))
+	for _, audioFile := range index.files {
+		clipes = append(clipes, s.clipeFromAPI(language, index.pubName, audioFile))
+	}
+
+	if s.enrichHTML {
+		s.enrichFromListing(language, index, clipes)
+	}
+
+	s.logger.Info("Lista de clipes obtida via API", "idioma", language, "total_clipes", len(clipes))
+	return clipes, nil
+}
+
+func (s *JWScraper) clipeFromAPI(language, album string, audioFile JWAudioFile) domain.ClipeMusical {
+	titulo := audioFile.Title
+
+	clipe := domain.ClipeMusical{
+		Titulo:         titulo,
+		URLDownload:    audioFile.File.URL,
+		TamanhoArquivo: int64(audioFile.FileSize),
+		Ano:            domain.YearFromTitle(titulo),
+		DataPublicacao: parseAPITime(audioFile.File.ModifiedDatetime),
+		Album:          album,
+		Faixa:          audioFile.Track,
+		Duracao:        audioFile.Duration,
+		Idioma:         language,
+	}
+
+	if audioFile.Track > 0 {
+		clipe.Chave = trackKey(audioFile.Pub, audioFile.Track)
+		clipe.ID = clipe.Chave
+		media, exists := s.source.TrackMedia[audioFile.Pub]
+		if !exists {
+			media = s.source.DefaultTrackMedia
+		}
+		query := strings.NewReplacer(
+			"{pub}", audioFile.Pub,
+			"{track}", strconv.Itoa(audioFile.Track),
+			"{media}", media,
+		).Replace(s.source.TrackQuery)
+		clipe.URL = s.finderURL(language, query)
+	}
+	if audioFile.DocID > 0 {
+		clipe.DocID = strconv.Itoa(audioFile.DocID)
+		clipe.ID = clipe.DocID
+		clipe.URL = s.finderURL(language, "docid="+clipe.DocID)
+	}
+	if clipe.URL == "" {
+		clipe.URL = audioFile.File.URL
+	}
+
+	if audioFile.TrackImage.URL != "" {
+		clipe.Imagens = []string{audioFile.TrackImage.URL}
+	}
+
+	return clipe
+}
+
+// enrichFromListing copies the page link, title and thumbnails of the listing
+// items onto the API clips they match. The listing is optional: when it
+// cannot be read the API data is used as is.
+func (s *JWScraper) enrichFromListing(language string, index *apiIndex, clipes []domain.ClipeMusical) {
+	listing, err := s.scrapListing(language)
+	if errors.Is(err, domain.ErrIncompleteCatalog) {
+		// The API holds the catalog, so a partial listing still helps.
+		s.logger.Warn("Listagem HTML incompleta", "idioma", language, "erro", err)
+	} else if err != nil {
+		s.logger.Warn("Listagem HTML indisponível, usando apenas a API", "idioma", language, "erro", err)
+		return
+	}
+
+	enriched := 0
+	for _, item := range listing {
+		i, _, found := index.find(clipeIdentifiers(item), item.Titulo)
+		if !found {
+			continue
+		}
+
+		clipe := &clipes[i]
+		clipe.ID = item.ID
+		clipe.URL = item.URL
+		clipe.Titulo = item.Titulo
+		if clipe.Ano == 0 {
+			clipe.Ano = item.Ano
+		}
+		for _, image := range clipe.Imagens {
+			item.Imagens = appendUnique(item.Imagens, image)
+		}
+		clipe.Imagens = item.Imagens
+		enriched++
+	}
+
+	s.logger.Info("Clipes enriquecidos com a listagem HTML", "idioma", language, "total", enriched)
+}
+
+// clipeIdentifiers recovers the identifiers of a clip scraped from the
+// listing, so it can be looked up in the API index.
+func clipeIdentifiers(clipe domain.ClipeMusical) listingIdentifiers {
+	ids := listingIdentifiers{DocID: clipe.DocID}
+	if i := strings.LastIndex(clipe.Chave, "-"); i > 0 {
+		ids.Pub = clipe.Chave[:i]
+		ids.Track, _ = strconv.Atoi(clipe.Chave[i+1:])
+	}
+	return ids
+}
+
+// finderURL links to the page the site redirects query to.
+func (s *JWScraper) finderURL(language, query string) string {
+	return expandURL(s.source.FinderURL, map[string]string{"language": language, "query": query})
+}
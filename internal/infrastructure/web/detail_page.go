@@ -0,0 +1,166 @@
+package web
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/sant0x00/downloader-music/internal/domain"
+)
+
+// clipPage holds what the detail page of a clip adds to the listing and the
+// API.
+type clipPage struct {
+	Descricao    string
+	Imagens      []string
+	Duracao      float64
+	Publicado    time.Time
+	Relacionados []int
+	Letra        string
+}
+
+var isoDurationPattern = regexp.MustCompile(`^P(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+(?:\.\d+)?)S)?)$`)
+
+// SetDetailPages makes ScrapClipeDetails read the page of each clip for its
+// description, images, duration, date and related songs. It costs one
+// request per clip, so it is off by default.
+func (s *JWScraper) SetDetailPages(enabled bool) {
+	s.detailPages = enabled
+}
+
+// clipPage fetches and parses the page of a clip once; lyrics and details
+// share the result.
+func (s *JWScraper) clipPage(clipe domain.ClipeMusical) (*clipPage, error) {
+	s.pagesMu.Lock()
+	page, cached := s.pages[clipe.URL]
+	s.pagesMu.Unlock()
+	if cached {
+		return page, nil
+	}
+
+	s.logger.Debug("Obtendo página do clipe", "titulo", clipe.Titulo, "url", clipe.URL)
+
+	doc, err := s.fetchDocument(clipe.URL, clipe.GetIdioma())
+	if err != nil {
+		return nil, err
+	}
+
+	page = s.parseClipPage(doc)
+
+	s.pagesMu.Lock()
+	s.pages[clipe.URL] = page
+	s.pagesMu.Unlock()
+	return page, nil
+}
+
+// enrichFromPage copies the detail page data onto the clip. The page is
+// optional: when it cannot be read the clip is left as it was.
+func (s *JWScraper) enrichFromPage(clipe *domain.ClipeMusical) {
+	page, err := s.clipPage(*clipe)
+	if err != nil {
+		s.logger.Warn("Erro ao obter página do clipe", "titulo", clipe.Titulo, "erro", err)
+		return
+	}
+
+	if page.Descricao != "" {
+		clipe.Descricao = page.Descricao
+	}
+	for _, image := range page.Imagens {
+		clipe.Imagens = appendUnique(clipe.Imagens, image)
+	}
+	if clipe.Duracao == 0 {
+		clipe.Duracao = page.Duracao
+	}
+	// The page date is when the clip was released; the API one only tells
+	// when the file last changed.
+	if !page.Publicado.IsZero() {
+		clipe.DataPublicacao = page.Publicado
+	}
+	if len(page.Relacionados) > 0 {
+		clipe.Relacionados = page.Relacionados
+	}
+}
+
+func (s *JWScraper) parseClipPage(doc *goquery.Document) *clipPage {
+	source := s.source
+	page := &clipPage{
+		Descricao: metaContent(doc, source.Description...),
+		Letra:     extractLyrics(doc, source.Lyrics),
+	}
+
+	for _, selector := range source.Images {
+		doc.Find(selector).Each(func(i int, meta *goquery.Selection) {
+			if value := strings.TrimSpace(meta.AttrOr("content", "")); strings.HasPrefix(value, "http") {
+				page.Imagens = appendUnique(page.Imagens, value)
+			}
+		})
+	}
+
+	page.Duracao = parseDuration(metaContent(doc, source.Duration...))
+	page.Publicado = parseAPITime(metaContent(doc, source.Published...))
+
+	page.Relacionados = s.relatedSongs(doc)
+	return page
+}
+
+// metaContent returns the content of the first meta tag found, or the
+// datetime of a <time> element.
+func metaContent(doc *goquery.Document, selectors ...string) string {
+	for _, selector := range selectors {
+		node := doc.Find(selector).First()
+		if value := strings.TrimSpace(node.AttrOr("content", node.AttrOr("datetime", ""))); value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// parseDuration reads durations given in seconds or as ISO 8601 (PT3M25S).
+func parseDuration(value string) float64 {
+	if seconds, err := strconv.ParseFloat(value, 64); err == nil {
+		return seconds
+	}
+
+	matches := isoDurationPattern.FindStringSubmatch(value)
+	if matches == nil {
+		return 0
+	}
+	hours, _ := strconv.ParseFloat(matches[1], 64)
+	minutes, _ := strconv.ParseFloat(matches[2], 64)
+	seconds, _ := strconv.ParseFloat(matches[3], 64)
+	return hours*3600 + minutes*60 + seconds
+}
+
+// relatedSongs collects the songbook numbers the page links to or mentions.
+func (s *JWScraper) relatedSongs(doc *goquery.Document) []int {
+	seen := make(map[int]bool)
+
+	body := doc.Find(s.source.RelatedArea)
+	if body.Length() == 0 {
+		body = doc.Find("body")
+	}
+
+	body.Find("a[href]").Each(func(i int, link *goquery.Selection) {
+		href := link.AttrOr("href", "")
+		if matches := trackPattern.FindStringSubmatch(href); matches != nil && strings.HasPrefix(matches[1], s.source.SongbookPub) {
+			if number, err := strconv.Atoi(matches[2]); err == nil {
+				seen[number] = true
+			}
+		}
+	})
+	for _, matches := range s.compiled.songReference.FindAllStringSubmatch(body.Text(), -1) {
+		if number, err := strconv.Atoi(matches[1]); err == nil && number > 0 {
+			seen[number] = true
+		}
+	}
+
+	songs := make([]int, 0, len(seen))
+	for number := range seen {
+		songs = append(songs, number)
+	}
+	sort.Ints(songs)
+	return songs
+}
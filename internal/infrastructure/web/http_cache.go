@@ -0,0 +1,192 @@
+package web
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// httpCache keeps the scraper responses on disk so unchanged pages and API
+// data are not downloaded again. Each response is a body file next to a
+// JSON file with its validators.
+type httpCache struct {
+	dir string
+	ttl time.Duration
+}
+
+type cachedResponse struct {
+	URL          string    `json:"url"`
+	ETag         string    `json:"etag,omitempty"`
+	LastModified string    `json:"last_modified,omitempty"`
+	FetchedAt    time.Time `json:"fetched_at"`
+	body         []byte
+}
+
+func (e *cachedResponse) fresh(ttl time.Duration) bool {
+	return time.Since(e.FetchedAt) < ttl
+}
+
+// response builds an HTTP response serving the cached body.
+func (e *cachedResponse) response(req *http.Request) *http.Response {
+	return &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        make(http.Header),
+		Body:          io.NopCloser(bytes.NewReader(e.body)),
+		ContentLength: int64(len(e.body)),
+		Request:       req,
+	}
+}
+
+// key tells responses apart by URL and language, as the site answers each
+// language differently.
+func (c *httpCache) key(req *http.Request) string {
+	sum := sha256.Sum256([]byte(req.URL.String() + "\n" + req.Header.Get("Accept-Language")))
+	return hex.EncodeToString(sum[:])
+}
+
+func (c *httpCache) paths(key string) (string, string) {
+	base := filepath.Join(c.dir, key[:2], key)
+	return base + ".json", base + ".body"
+}
+
+// load returns nil when the response is not cached or cannot be read.
+func (c *httpCache) load(req *http.Request) *cachedResponse {
+	metaPath, bodyPath := c.paths(c.key(req))
+
+	data, err := os.ReadFile(metaPath)
+	if err != nil {
+		return nil
+	}
+	var entry cachedResponse
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil
+	}
+	if entry.body, err = os.ReadFile(bodyPath); err != nil {
+		return nil
+	}
+	return &entry
+}
+
+func (c *httpCache) store(req *http.Request, entry *cachedResponse) error {
+	metaPath, bodyPath := c.paths(c.key(req))
+	if err := os.MkdirAll(filepath.Dir(metaPath), 0755); err != nil {
+		return fmt.Errorf("erro ao criar diretório do cache: %w", err)
+	}
+
+	meta, err := json.MarshalIndent(entry, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := writeFileAtomic(bodyPath, entry.body); err != nil {
+		return err
+	}
+	return writeFileAtomic(metaPath, meta)
+}
+
+// writeFileAtomic keeps concurrent readers from seeing half a file.
+func writeFileAtomic(path string, data []byte) error {
+	tempFile := path + ".tmp"
+	if err := os.WriteFile(tempFile, data, 0644); err != nil {
+		return fmt.Errorf("erro ao gravar cache: %w", err)
+	}
+	if err := os.Rename(tempFile, path); err != nil {
+		os.Remove(tempFile)
+		return fmt.Errorf("erro ao gravar cache: %w", err)
+	}
+	return nil
+}
+
+// SetCache keeps the responses in dir, reusing them without a request for
+// ttl and revalidating them with ETag/Last-Modified afterwards.
+func (s *JWScraper) SetCache(dir string, ttl time.Duration) {
+	if dir == "" {
+		s.cache = nil
+		return
+	}
+	s.cache = &httpCache{dir: dir, ttl: ttl}
+}
+
+// SetOffline serves every request from the cache, failing on the ones that
+// were never cached.
+func (s *JWScraper) SetOffline(offline bool) {
+	s.offline = offline
+}
+
+// cachedRequest answers req from the cache when possible, otherwise sends
+// it and caches the response.
+func (s *JWScraper) cachedRequest(req *http.Request) (*http.Response, error) {
+	entry := s.cache.load(req)
+
+	if s.offline {
+		if entry == nil {
+			return nil, fmt.Errorf("%s não está no cache (modo offline)", req.URL)
+		}
+		return entry.response(req), nil
+	}
+	if entry != nil && entry.fresh(s.cache.ttl) {
+		s.logger.Debug("Resposta obtida do cache", "url", req.URL.String())
+		return entry.response(req), nil
+	}
+
+	if entry != nil {
+		if entry.ETag != "" {
+			req.Header.Set("If-None-Match", entry.ETag)
+		}
+		if entry.LastModified != "" {
+			req.Header.Set("If-Modified-Since", entry.LastModified)
+		}
+	}
+
+	s.limiter.Wait()
+	resp, err := s.client.Do(req)
+	if err != nil {
+		if entry != nil {
+			s.logger.Warn("Falha na requisição, usando cópia do cache", "url", req.URL.String(), "erro", err.Error())
+			return entry.response(req), nil
+		}
+		return nil, err
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusNotModified && entry != nil:
+		resp.Body.Close()
+		s.logger.Debug("Resposta não modificada, usando cache", "url", req.URL.String())
+		entry.FetchedAt = time.Now()
+		if err := s.cache.store(req, entry); err != nil {
+			s.logger.Warn("Não foi possível atualizar o cache", "url", req.URL.String(), "erro", err.Error())
+		}
+		return entry.response(req), nil
+
+	case resp.StatusCode == http.StatusOK:
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao ler resposta: %w", err)
+		}
+		fetched := &cachedResponse{
+			URL:          req.URL.String(),
+			ETag:         resp.Header.Get("ETag"),
+			LastModified: resp.Header.Get("Last-Modified"),
+			FetchedAt:    time.Now(),
+			body:         body,
+		}
+		if err := s.cache.store(req, fetched); err != nil {
+			s.logger.Warn("Não foi possível gravar o cache", "url", req.URL.String(), "erro", err.Error())
+		}
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+		return resp, nil
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,196 @@
+package web
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// cacheServer answers /etag with an ETag and /modified with Last-Modified,
+// honouring conditional requests. The body of /etag changes with version.
+func cacheServer(t *testing.T) (*httptest.Server, *int, *string) {
+	t.Helper()
+
+	requests := 0
+	version := "v1"
+	lastModified := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC).Format(http.TimeFormat)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		switch r.URL.Path {
+		case "/etag":
+			etag := `"` + version + `"`
+			w.Header().Set("ETag", etag)
+			if r.Header.Get("If-None-Match") == etag {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			io.WriteString(w, "corpo "+version)
+		case "/modified":
+			w.Header().Set("Last-Modified", lastModified)
+			if r.Header.Get("If-Modified-Since") == lastModified {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			io.WriteString(w, "corpo datado")
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return server, &requests, &version
+}
+
+func newCachedScraper(t *testing.T, server *httptest.Server, ttl time.Duration) *JWScraper {
+	t.Helper()
+	scraper := newTestScraper(t, server)
+	scraper.SetCache(t.TempDir(), ttl)
+	return scraper
+}
+
+func fetchBody(t *testing.T, scraper *JWScraper, url string) (string, error) {
+	t.Helper()
+
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		t.Fatalf("request: %v", err)
+	}
+	resp, err := scraper.doRequest(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("body: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want 200", resp.StatusCode)
+	}
+	return string(body), nil
+}
+
+func TestHTTPCacheFreshWithinTTL(t *testing.T) {
+	server, requests, _ := cacheServer(t)
+	scraper := newCachedScraper(t, server, time.Hour)
+
+	for i := 0; i < 3; i++ {
+		body, err := fetchBody(t, scraper, server.URL+"/etag")
+		if err != nil || body != "corpo v1" {
+			t.Fatalf("fetch %d = %q, %v", i, body, err)
+		}
+	}
+	if *requests != 1 {
+		t.Errorf("requests = %d, want 1", *requests)
+	}
+}
+
+func TestHTTPCacheRevalidation(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+		body string
+	}{
+		{"etag", "/etag", "corpo v1"},
+		{"last modified", "/modified", "corpo datado"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, requests, _ := cacheServer(t)
+			// A zero TTL makes every cached response stale right away.
+			scraper := newCachedScraper(t, server, 0)
+
+			for i := 0; i < 2; i++ {
+				body, err := fetchBody(t, scraper, server.URL+tt.path)
+				if err != nil || body != tt.body {
+					t.Fatalf("fetch %d = %q, %v; want %q", i, body, err, tt.body)
+				}
+			}
+			// The second request was a 304 answered with the cached body.
+			if *requests != 2 {
+				t.Errorf("requests = %d, want 2", *requests)
+			}
+		})
+	}
+}
+
+func TestHTTPCacheExpiredAndChanged(t *testing.T) {
+	server, requests, version := cacheServer(t)
+	scraper := newCachedScraper(t, server, time.Hour)
+
+	if body, err := fetchBody(t, scraper, server.URL+"/etag"); err != nil || body != "corpo v1" {
+		t.Fatalf("first fetch = %q, %v", body, err)
+	}
+
+	*version = "v2"
+	if body, _ := fetchBody(t, scraper, server.URL+"/etag"); body != "corpo v1" {
+		t.Errorf("fresh fetch = %q, want the cached body", body)
+	}
+
+	scraper.cache.ttl = 0
+	if body, _ := fetchBody(t, scraper, server.URL+"/etag"); body != "corpo v2" {
+		t.Errorf("expired fetch = %q, want the new body", body)
+	}
+	if *requests != 2 {
+		t.Errorf("requests = %d, want 2", *requests)
+	}
+
+	// The new body replaced the cached one.
+	scraper.cache.ttl = time.Hour
+	if body, _ := fetchBody(t, scraper, server.URL+"/etag"); body != "corpo v2" {
+		t.Errorf("cached fetch = %q, want the new body", body)
+	}
+}
+
+func TestHTTPCacheOffline(t *testing.T) {
+	server, requests, _ := cacheServer(t)
+	scraper := newCachedScraper(t, server, 0)
+
+	if _, err := fetchBody(t, scraper, server.URL+"/etag"); err != nil {
+		t.Fatalf("online fetch: %v", err)
+	}
+
+	scraper.SetOffline(true)
+	if body, err := fetchBody(t, scraper, server.URL+"/etag"); err != nil || body != "corpo v1" {
+		t.Errorf("offline hit = %q, %v; want the stale cached body", body, err)
+	}
+	_, err := fetchBody(t, scraper, server.URL+"/modified")
+	if err == nil || !strings.Contains(err.Error(), "modo offline") {
+		t.Errorf("offline miss error = %v", err)
+	}
+	if *requests != 1 {
+		t.Errorf("requests = %d, want only the online one", *requests)
+	}
+}
+
+func TestHTTPCacheOfflineWithoutCache(t *testing.T) {
+	server, requests, _ := cacheServer(t)
+	scraper := newTestScraper(t, server)
+	scraper.SetOffline(true)
+
+	if _, err := fetchBody(t, scraper, server.URL+"/etag"); err == nil {
+		t.Error("offline without cache succeeded")
+	}
+	if *requests != 0 {
+		t.Errorf("requests = %d, want 0", *requests)
+	}
+}
+
+func TestHTTPCacheServerDown(t *testing.T) {
+	server, _, _ := cacheServer(t)
+	scraper := newCachedScraper(t, server, 0)
+
+	if _, err := fetchBody(t, scraper, server.URL+"/etag"); err != nil {
+		t.Fatalf("online fetch: %v", err)
+	}
+
+	server.Close()
+	if body, err := fetchBody(t, scraper, server.URL+"/etag"); err != nil || body != "corpo v1" {
+		t.Errorf("fetch with server down = %q, %v; want the cached body", body, err)
+	}
+}
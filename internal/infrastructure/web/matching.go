@@ -0,0 +1,195 @@
+package web
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/PuerkitoBio/goquery"
+	"golang.org/x/text/unicode/norm"
+)
+
+var (
+	// Listing links and images expose the document id in several shapes:
+	// ?docid=1102021434, data-page-id="mid1102021434" or /img/p/1102021434/.
+	docIDPattern = regexp.MustCompile(`(?:docid=|mid|/img/p/)(\d{6,})`)
+	// Media keys such as lank=pub-osg_12_VIDEO or pub=osg&track=12.
+	lankPattern  = regexp.MustCompile(`pub-([a-z0-9]+)_(\d+)_`)
+	trackPattern = regexp.MustCompile(`pub=([a-z0-9]+)&(?:amp;)?track=(\d+)`)
+	// File names of the pub-media API, e.g. osg_T_012.mp3.
+	fileTrackPattern = regexp.MustCompile(`/([a-z0-9]+)_[A-Z0-9-]+_(\d+)\.[a-z0-9]+$`)
+)
+
+// listingIdentifiers collects the stable identifiers a listing item exposes
+// in its link, attributes and images.
+type listingIdentifiers struct {
+	DocID string
+	Pub   string
+	Track int
+}
+
+func extractIdentifiers(item *goquery.Selection, href string) listingIdentifiers {
+	var sources []string
+	sources = append(sources, href)
+
+	item.Find("*").AddSelection(item).Each(func(i int, node *goquery.Selection) {
+		for _, attr := range node.Nodes[0].Attr {
+			if attr.Key == "href" || attr.Key == "src" || strings.HasPrefix(attr.Key, "data-") {
+				sources = append(sources, attr.Val)
+			}
+		}
+	})
+
+	var ids listingIdentifiers
+	for _, source := range sources {
+		if ids.DocID == "" {
+			if matches := docIDPattern.FindStringSubmatch(source); matches != nil {
+				ids.DocID = matches[1]
+			}
+		}
+		if ids.Track == 0 {
+			matches := lankPattern.FindStringSubmatch(source)
+			if matches == nil {
+				matches = trackPattern.FindStringSubmatch(source)
+			}
+			if matches != nil {
+				ids.Pub = matches[1]
+				ids.Track, _ = strconv.Atoi(matches[2])
+			}
+		}
+	}
+	return ids
+}
+
+// normalizeTitle reduces a title to lowercase words without accents,
+// separated by single spaces, so quotes, punctuation and spacing differences
+// do not matter.
+func normalizeTitle(title string) string {
+	var b strings.Builder
+	for _, char := range norm.NFKD.String(title) {
+		switch {
+		case unicode.Is(unicode.Mn, char), char == '\'', char == '’':
+			// Accents and apostrophes are dropped without splitting the word.
+		case unicode.IsLetter(char), unicode.IsDigit(char):
+			b.WriteRune(unicode.ToLower(char))
+		default:
+			b.WriteRune(' ')
+		}
+	}
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+// ambiguousMatch marks index keys shared by more than one file.
+const ambiguousMatch = -1
+
+func trackKey(pub string, track int) string {
+	return fmt.Sprintf("%s-%d", strings.ToLower(pub), track)
+}
+
+// apiIndex holds the pub-media files of a language and remembers which of
+// them were claimed by a listing item.
+type apiIndex struct {
+	pubCode string
+	pubName string
+	files   []JWAudioFile
+	byDocID map[string]int
+	byTrack map[string]int
+	byTitle map[string]int
+	mu      sync.Mutex // guards matched
+	matched map[int]bool
+}
+
+func newAPIIndex(pub string, files []JWAudioFile) *apiIndex {
+	index := &apiIndex{
+		pubCode: pub,
+		files:   files,
+		byDocID: make(map[string]int),
+		byTrack: make(map[string]int),
+		byTitle: make(map[string]int),
+		matched: make(map[int]bool),
+	}
+
+	for i, file := range files {
+		if file.DocID > 0 {
+			index.byDocID[strconv.Itoa(file.DocID)] = i
+		}
+
+		filePub, track := file.Pub, file.Track
+		if matches := fileTrackPattern.FindStringSubmatch(file.File.URL); matches != nil {
+			if filePub == "" {
+				filePub = matches[1]
+			}
+			if track == 0 {
+				track, _ = strconv.Atoi(matches[2])
+			}
+		}
+		if filePub == "" {
+			filePub = pub
+		}
+		index.files[i].Pub, index.files[i].Track = filePub, track
+		if track > 0 {
+			addUnique(index.byTrack, trackKey(filePub, track), i)
+		}
+
+		// Untitled files can only be matched by their identifiers.
+		if title := normalizeTitle(file.Title); title != "" {
+			addUnique(index.byTitle, title, i)
+		}
+	}
+
+	return index
+}
+
+// addUnique indexes key to i, or marks it ambiguous when another file
+// already claimed it.
+func addUnique(index map[string]int, key string, i int) {
+	if _, exists := index[key]; exists {
+		index[key] = ambiguousMatch
+		return
+	}
+	index[key] = i
+}
+
+// find looks a listing item up by document id, then publication and track,
+// then normalized title. Keys shared by several files are skipped.
+func (x *apiIndex) find(ids listingIdentifiers, titulo string) (int, string, bool) {
+	candidates := []struct {
+		method string
+		index  map[string]int
+		key    string
+	}{
+		{"docid", x.byDocID, ids.DocID},
+		{"faixa", x.byTrack, trackKey(ids.Pub, ids.Track)},
+		{"titulo", x.byTitle, normalizeTitle(titulo)},
+	}
+
+	for _, candidate := range candidates {
+		if candidate.key == "" || (candidate.method == "faixa" && ids.Track == 0) {
+			continue
+		}
+		if i, exists := candidate.index[candidate.key]; exists && i != ambiguousMatch {
+			x.mu.Lock()
+			x.matched[i] = true
+			x.mu.Unlock()
+			return i, candidate.method, true
+		}
+	}
+
+	return -1, "", false
+}
+
+func (x *apiIndex) unmatched() []JWAudioFile {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	var files []JWAudioFile
+	for i, file := range x.files {
+		if !x.matched[i] {
+			files = append(files, file)
+		}
+	}
+	return files
+}
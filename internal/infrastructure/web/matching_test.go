@@ -0,0 +1,61 @@
+package web
+
+import "testing"
+
+func TestAPIIndexFind(t *testing.T) {
+	files := []JWAudioFile{
+		{Title: "Alegria", DocID: 1102021434, File: JWFile{URL: "https://cdn.example/sjjm_T_001.mp3"}},
+		{Title: "Coragem", File: JWFile{URL: "https://cdn.example/sjjm_T_002.mp3"}},
+		{Title: "Gratidão", Pub: "sjjm", Track: 3},
+		{Title: "Gratidao!", Pub: "sjjm", Track: 4},
+		{Title: "Paz", Pub: "sjjm", Track: 5},
+		{Title: "Paz (versão longa)", Pub: "sjjm", Track: 5},
+		{Title: "Jehovah’s Way", Pub: "osg", Track: 1},
+		{Title: ""},
+	}
+
+	tests := []struct {
+		name   string
+		ids    listingIdentifiers
+		titulo string
+		want   int
+		method string
+	}{
+		{"docid wins over title", listingIdentifiers{DocID: "1102021434"}, "Coragem", 0, "docid"},
+		{"docid wins over track", listingIdentifiers{DocID: "1102021434", Pub: "sjjm", Track: 2}, "", 0, "docid"},
+		{"missing docid falls back to track", listingIdentifiers{DocID: "999999", Pub: "sjjm", Track: 2}, "", 1, "faixa"},
+		{"track from file name", listingIdentifiers{Pub: "SJJM", Track: 1}, "", 0, "faixa"},
+		{"track wins over title", listingIdentifiers{Pub: "sjjm", Track: 4}, "Alegria", 3, "faixa"},
+		{"duplicate track falls back to title", listingIdentifiers{Pub: "sjjm", Track: 5}, "Paz", 4, "titulo"},
+		{"track of another publication", listingIdentifiers{Pub: "osg", Track: 2}, "Coragem", 1, "titulo"},
+		{"title ignores case and punctuation", listingIdentifiers{}, "  coragem. ", 1, "titulo"},
+		{"title ignores apostrophes", listingIdentifiers{}, "Jehovah's Way", 6, "titulo"},
+		{"ambiguous title", listingIdentifiers{}, "Gratidão", -1, ""},
+		{"duplicate track without title", listingIdentifiers{Pub: "sjjm", Track: 5}, "", -1, ""},
+		{"empty title", listingIdentifiers{}, "", -1, ""},
+		{"unknown", listingIdentifiers{DocID: "999999"}, "Esperança", -1, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			index := newAPIIndex("sjjm", append([]JWAudioFile(nil), files...))
+			i, method, found := index.find(tt.ids, tt.titulo)
+			if i != tt.want || method != tt.method || found != (tt.want >= 0) {
+				t.Errorf("find(%+v, %q) = %d, %q, %v; want %d, %q", tt.ids, tt.titulo, i, method, found, tt.want, tt.method)
+			}
+		})
+	}
+}
+
+func TestAPIIndexUnmatched(t *testing.T) {
+	index := newAPIIndex("sjjm", []JWAudioFile{
+		{Title: "Alegria", Track: 1},
+		{Title: "Coragem", Track: 2},
+	})
+	index.find(listingIdentifiers{Pub: "sjjm", Track: 2}, "")
+
+	unmatched := index.unmatched()
+	if len(unmatched) != 1 || unmatched[0].Title != "Alegria" {
+		t.Errorf("unmatched = %+v, want only Alegria", unmatched)
+	}
+}
@@ -1,257 +1,670 @@
 package web
 
 import (
-	"encoding/json"
 	"fmt"
 	"net/http"
-	"regexp"
+	neturl "net/url"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/PuerkitoBio/goquery"
 	"github.com/sant0x00/downloader-music/internal/domain"
 )
 
+// JWAPIResponse is the pub-media JSON, decoded through the field names of
+// the source.
 type JWAPIResponse struct {
-	PubName string                     `json:"pubName"`
-	Files   map[string]JWLanguageFiles `json:"files"`
+	Pub     string
+	PubName string
+	Files   map[string]JWLanguageFiles
 }
 
-type JWLanguageFiles struct {
-	MP3 []JWAudioFile `json:"MP3"`
+// JWLanguageFiles holds the files of a language by format, e.g. MP3.
+type JWLanguageFiles map[string][]JWAudioFile
+
+func (f JWLanguageFiles) byFormat(format string) []JWAudioFile {
+	return f[format]
 }
 
 type JWAudioFile struct {
-	Title    string `json:"title"`
-	File     JWFile `json:"file"`
-	FileSize int    `json:"filesize"`
+	Title      string
+	DocID      int
+	Pub        string
+	Track      int
+	File       JWFile
+	FileSize   int
+	Duration   float64
+	TrackImage JWImage
+}
+
+type JWImage struct {
+	URL string
 }
 
 type JWFile struct {
-	URL              string `json:"url"`
-	ModifiedDatetime string `json:"modifiedDatetime"`
-	Checksum         string `json:"checksum"`
+	URL              string
+	ModifiedDatetime string
+	Checksum         string
 }
 
+const defaultMaxPages = 20
+
 type JWScraper struct {
-	client        *http.Client
-	userAgent     string
-	delay         time.Duration
-	logger        domain.Logger
-	downloadURL   string
-	downloadCache map[string]string
+	client      *http.Client
+	userAgent   string
+	logger      domain.Logger
+	languages   []string
+	listingURLs map[string]string
+	limiter     *rateLimiter
+	cache       *httpCache
+	offline     bool
+	source      Source
+	compiled    compiledSource
+	mode        ScrapingMode
+	pub         string
+	formats     []string // preferred first
+	enrichHTML  bool
+	maxPages    int
+
+	cacheMu       sync.Mutex
+	downloadCache map[string]*apiIndex // by language
+
+	detailPages bool
+	pagesMu     sync.Mutex
+	pages       map[string]*clipPage // by page URL
 }
 
 func NewJWScraper(userAgent string, delay time.Duration, logger domain.Logger) *JWScraper {
+	var limiter *rateLimiter
+	if delay > 0 {
+		limiter = newRateLimiter(1/delay.Seconds(), 1)
+	}
+
+	source := DefaultSource()
+	compiled, err := source.validate()
+	if err != nil {
+		panic(fmt.Sprintf("fonte padrão inválida: %v", err))
+	}
+
 	return &JWScraper{
+		source:   source,
+		compiled: compiled,
+		limiter:  limiter,
 		client: &http.Client{
 			Timeout: 30 * time.Second,
 		},
 		userAgent:     userAgent,
-		delay:         delay,
 		logger:        logger,
-		downloadURL:   "https://b.jw-cdn.org/apis/pub-media/GETPUBMEDIALINKS?output=json&pub=osg&fileformat=MP3%2CAAC&alllangs=0&langwritten=T&txtCMSLang=T",
-		downloadCache: make(map[string]string),
+		languages:     []string{domain.DefaultLanguage},
+		listingURLs:   make(map[string]string),
+		downloadCache: make(map[string]*apiIndex),
+		mode:          ScrapingModeHTML,
+		maxPages:      defaultMaxPages,
+		pub:           domain.DefaultPublication,
+		formats:       []string{"MP3"},
+		pages:         make(map[string]*clipPage),
 	}
 }
 
-func (s *JWScraper) ScrapClipesList(url string) ([]domain.ClipeMusical, error) {
-	s.logger.Info("Iniciando scraping da lista de clipes", "url", url)
+// SetLanguages sets the languages whose catalog FetchRemoteFiles returns.
+func (s *JWScraper) SetLanguages(languages []string) {
+	s.languages = languages
+}
+
+// SetPublication chooses the publication whose files are fetched and the
+// formats to download, most preferred first. Cached API data is dropped.
+func (s *JWScraper) SetPublication(pub string, formats []string) {
+	s.pub = pub
+	s.formats = formats
 
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return nil, fmt.Errorf("erro ao criar requisição: %w", err)
+	s.cacheMu.Lock()
+	s.downloadCache = make(map[string]*apiIndex)
+	s.cacheMu.Unlock()
+}
+
+// SetRateLimit limits every request of the scraper to perSecond requests per
+// second, allowing bursts of burst requests. It replaces the limit derived
+// from the delay between requests; zero removes it.
+func (s *JWScraper) SetRateLimit(perSecond float64, burst int) {
+	s.limiter = newRateLimiter(perSecond, burst)
+}
+
+// preferredFiles returns the files of the first preferred format the
+// language was published in.
+func (s *JWScraper) preferredFiles(langFiles JWLanguageFiles) []JWAudioFile {
+	for _, format := range s.formats {
+		if files := langFiles.byFormat(format); len(files) > 0 {
+			return files
+		}
 	}
+	return nil
+}
 
-	req.Header.Set("User-Agent", s.userAgent)
-	req.Header.Set("Accept", "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8")
-	req.Header.Set("Accept-Language", "pt-BR,pt;q=0.9,en;q=0.8")
+// SetMaxPages caps how many listing pages are followed per language.
+func (s *JWScraper) SetMaxPages(maxPages int) {
+	if maxPages > 0 {
+		s.maxPages = maxPages
+	}
+}
 
-	resp, err := s.client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("erro ao fazer requisição: %w", err)
+// SetListingURL overrides the listing page of a language, which is required
+// for languages without a built-in page.
+func (s *JWScraper) SetListingURL(language, listingURL string) {
+	s.listingURLs[language] = listingURL
+}
+
+func (s *JWScraper) listingURL(language string) (string, error) {
+	if listingURL, exists := s.listingURLs[language]; exists && listingURL != "" {
+		return listingURL, nil
 	}
-	defer resp.Body.Close()
+	if known, exists := s.source.Languages[language]; exists {
+		return known.ListingURL, nil
+	}
+	return "", fmt.Errorf("página de clipes desconhecida para o idioma %s (defina scraping.listing_urls)", language)
+}
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("status code inválido: %d", resp.StatusCode)
+func (s *JWScraper) acceptLanguage(language string) string {
+	if known, exists := s.source.Languages[language]; exists && known.Locale != "pt" {
+		return known.Locale + ",pt-BR;q=0.8"
 	}
+	return "pt-BR,pt;q=0.9,en;q=0.8"
+}
 
-	doc, err := goquery.NewDocumentFromReader(resp.Body)
+// ScrapClipesList returns the catalog of a language, read from the listing
+// page or, in API mode, straight from the pub-media files.
+func (s *JWScraper) ScrapClipesList(language string) ([]domain.ClipeMusical, error) {
+	if s.mode == ScrapingModeAPI {
+		return s.listFromAPI(language)
+	}
+	return s.scrapListing(language)
+}
+
+// scrapListing reads the listing page of a language and follows its
+// pagination or "load more" links until they run out. A page that fails
+// after the first, or reaching maxPages with pages left, returns the clips
+// read so far with ErrIncompleteCatalog.
+// Items repeated across pages are kept once.
+func (s *JWScraper) scrapListing(language string) ([]domain.ClipeMusical, error) {
+	listingURL, err := s.listingURL(language)
 	if err != nil {
-		return nil, fmt.Errorf("erro ao parsear HTML: %w", err)
+		return nil, err
+	}
+
+	s.logger.Info("Iniciando scraping da lista de clipes", "url", listingURL, "idioma", language)
+
+	listing, err := neturl.Parse(listingURL)
+	if err != nil {
+		return nil, fmt.Errorf("URL de listagem inválida: %w", err)
 	}
 
 	var clipes []domain.ClipeMusical
+	seen := make(map[string]bool)
+	visited := make(map[string]bool)
+
+	pageURL := listing
+	for page := 1; pageURL != nil; page++ {
+		if page > s.maxPages {
+			s.logger.Warn("Limite de páginas da listagem atingido", "idioma", language, "limite", s.maxPages)
+			return clipes, fmt.Errorf("%w: limite de %d páginas atingido", domain.ErrIncompleteCatalog, s.maxPages)
+		}
+		visited[pageURL.String()] = true
+
+		doc, err := s.fetchDocument(pageURL.String(), language)
+		if err != nil {
+			if page == 1 {
+				return nil, err
+			}
+			s.logger.Warn("Erro ao obter página da listagem", "pagina", page, "erro", err)
+			return clipes, fmt.Errorf("%w: página %d: %v", domain.ErrIncompleteCatalog, page, err)
+		}
 
-	doc.Find("h2 a[href*='/biblioteca/musica-canticos/clipes-musicais/']").Each(func(i int, sel *goquery.Selection) {
+		added := 0
+		for _, clipe := range s.parseListingPage(doc, listing, language) {
+			if seen[clipe.URL] {
+				continue
+			}
+			seen[clipe.URL] = true
+			clipes = append(clipes, clipe)
+			added++
+		}
+		s.logger.Debug("Página da listagem processada", "pagina", page, "novos", added)
+
+		// A page with nothing new means the site is serving the same items.
+		if added == 0 && page > 1 {
+			break
+		}
+
+		pageURL = nextPageURL(doc, pageURL, s.source.NextPage)
+		if pageURL != nil && visited[pageURL.String()] {
+			break
+		}
+	}
+
+	s.logger.Info("Scraping da lista concluído", "total_clipes", len(clipes))
+	return clipes, nil
+}
+
+func (s *JWScraper) parseListingPage(doc *goquery.Document, listing *neturl.URL, language string) []domain.ClipeMusical {
+	var clipes []domain.ClipeMusical
+
+	// Clip pages live below the listing page in every language.
+	selector := strings.ReplaceAll(s.source.ListingLink, "{path}", strings.TrimPrefix(listing.Path, "/"+jwLocale(listing.Path)))
+	doc.Find(selector).Each(func(i int, sel *goquery.Selection) {
 		href, exists := sel.Attr("href")
 		if !exists {
 			return
 		}
 
-		titulo := strings.TrimSpace(sel.Text())
+		titulo := s.cleanTitle(sel.Text())
 		if titulo == "" {
 			return
 		}
 
 		fullURL := href
 		if strings.HasPrefix(href, "/") {
-			fullURL = "https://www.jw.org" + href
+			fullURL = listing.Scheme + "://" + listing.Host + href
 		}
 
 		id := s.extractClipeID(href)
+		ids := extractIdentifiers(s.listingItem(sel), href)
 
-		ano := s.extractYearFromTitle(titulo)
+		ano := domain.YearFromTitle(titulo)
 
 		clipe := domain.ClipeMusical{
-			ID:     id,
-			Titulo: titulo,
-			URL:    fullURL,
-			Ano:    ano,
+			ID:      id,
+			DocID:   ids.DocID,
+			Titulo:  titulo,
+			URL:     fullURL,
+			Ano:     ano,
+			Imagens: s.extractImageURLs(sel),
+			Idioma:  language,
+		}
+		if ids.Track > 0 {
+			clipe.Chave = trackKey(ids.Pub, ids.Track)
 		}
 
 		clipes = append(clipes, clipe)
 	})
 
-	s.logger.Info("Scraping da lista concluído", "total_clipes", len(clipes))
-	return clipes, nil
+	return clipes
+}
+
+// nextPageURL follows the first link to the next page of a listing, such as
+// rel=next links, pagination arrows and "load more" buttons.
+func nextPageURL(doc *goquery.Document, current *neturl.URL, selectors []string) *neturl.URL {
+	for _, selector := range selectors {
+		node := doc.Find(selector).First()
+		if node.Length() == 0 {
+			continue
+		}
+
+		href := node.AttrOr("data-load-more-url", node.AttrOr("href", ""))
+		if href == "" || strings.HasPrefix(href, "#") || strings.HasPrefix(href, "javascript:") {
+			continue
+		}
+		next, err := current.Parse(href)
+		if err != nil {
+			continue
+		}
+		return next
+	}
+	return nil
+}
+
+// doRequest sends a request once the rate limit allows it, going through
+// the cache when there is one.
+func (s *JWScraper) doRequest(req *http.Request) (*http.Response, error) {
+	if s.cache != nil {
+		return s.cachedRequest(req)
+	}
+	if s.offline {
+		return nil, fmt.Errorf("o modo offline exige o cache HTTP (cache.enabled)")
+	}
+	s.limiter.Wait()
+	return s.client.Do(req)
+}
+
+// fetchDocument downloads and parses an HTML page of the site.
+func (s *JWScraper) fetchDocument(pageURL, language string) (*goquery.Document, error) {
+	req, err := http.NewRequest("GET", pageURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao criar requisição: %w", err)
+	}
+
+	req.Header.Set("User-Agent", s.userAgent)
+	req.Header.Set("Accept", "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8")
+	req.Header.Set("Accept-Language", s.acceptLanguage(language))
+
+	resp, err := s.doRequest(req)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao fazer requisição: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("status code inválido: %d", resp.StatusCode)
+	}
+
+	doc, err := goquery.NewDocumentFromReader(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao parsear HTML: %w", err)
+	}
+	return doc, nil
 }
 
 func (s *JWScraper) ScrapClipeDetails(clipe domain.ClipeMusical) (domain.ClipeMusical, error) {
 	s.logger.Debug("Obtendo detalhes do clipe", "titulo", clipe.Titulo, "url", clipe.URL)
 
-	downloadURL, err := s.findDownloadURLForClipe(clipe.Titulo)
+	// API clips already carry everything the listing would be matched for.
+	if s.mode != ScrapingModeAPI || clipe.URLDownload == "" {
+		if err := s.attachAudioFile(&clipe); err != nil {
+			return clipe, err
+		}
+	}
+
+	if s.detailPages && clipe.URL != "" {
+		s.enrichFromPage(&clipe)
+	}
+
+	s.logger.Debug("Detalhes obtidos", "titulo", clipe.Titulo, "download_url", clipe.URLDownload, "ano", clipe.Ano)
+	return clipe, nil
+}
+
+// attachAudioFile fills in the download of a listing clip from the API.
+func (s *JWScraper) attachAudioFile(clipe *domain.ClipeMusical) error {
+	audioFile, index, found, err := s.findAudioFileForClipe(*clipe)
 	if err != nil {
 		s.logger.Error("Erro ao buscar URL de download", err, "titulo", clipe.Titulo)
-		return clipe, err
+		return err
 	}
 
-	if downloadURL != "" {
-		clipe.URLDownload = downloadURL
-		s.logger.Debug("URL de download encontrada", "titulo", clipe.Titulo, "url", downloadURL)
+	if found {
+		clipe.URLDownload = audioFile.File.URL
+		clipe.Faixa = audioFile.Track
+		clipe.Album = index.pubName
+		if audioFile.Track > 0 {
+			clipe.Chave = trackKey(index.pubCode, audioFile.Track)
+		}
+		if clipe.DocID == "" && audioFile.DocID > 0 {
+			clipe.DocID = strconv.Itoa(audioFile.DocID)
+		}
+		clipe.Duracao = audioFile.Duration
+		clipe.TamanhoArquivo = int64(audioFile.FileSize)
+		clipe.DataPublicacao = parseAPITime(audioFile.File.ModifiedDatetime)
+		if audioFile.TrackImage.URL != "" {
+			clipe.Imagens = appendUnique(clipe.Imagens, audioFile.TrackImage.URL)
+		}
+		s.logger.Debug("URL de download encontrada", "titulo", clipe.Titulo, "url", clipe.URLDownload)
 	}
+	return nil
+}
 
-	if clipe.Ano == 0 {
-		clipe.Ano = s.extractYearFromTitle(clipe.Titulo)
+// FetchLyrics returns the lyrics block of the clip page. Each page is fetched
+// at most once, as the details, the tagger and the sidecar writer ask for it.
+func (s *JWScraper) FetchLyrics(clipe domain.ClipeMusical) (string, error) {
+	if clipe.URL == "" {
+		return "", fmt.Errorf("clipe sem página: %s", clipe.Titulo)
 	}
 
-	time.Sleep(s.delay)
+	page, err := s.clipPage(clipe)
+	if err != nil {
+		return "", err
+	}
 
-	clipe.Descricao = fmt.Sprintf("Clipe musical: %s", clipe.Titulo)
+	if page.Letra == "" {
+		return "", fmt.Errorf("letra não encontrada na página")
+	}
+	return page.Letra, nil
+}
 
-	s.logger.Debug("Detalhes obtidos", "titulo", clipe.Titulo, "download_url", clipe.URLDownload, "ano", clipe.Ano)
-	return clipe, nil
+// extractLyrics turns the paragraphs of the first lyrics block found into
+// stanzas, one line per <br>.
+func extractLyrics(doc *goquery.Document, selectors []string) string {
+	var body *goquery.Selection
+	for _, selector := range selectors {
+		if body = doc.Find(selector); body.Length() > 0 {
+			break
+		}
+	}
+
+	var stanzas []string
+	body.First().Find("p").Each(func(i int, p *goquery.Selection) {
+		p.Find("br").ReplaceWithHtml("\n")
+
+		var lines []string
+		for _, line := range strings.Split(p.Text(), "\n") {
+			if line = strings.Join(strings.Fields(line), " "); line != "" {
+				lines = append(lines, line)
+			}
+		}
+		if len(lines) > 0 {
+			stanzas = append(stanzas, strings.Join(lines, "\n"))
+		}
+	})
+
+	return strings.Join(stanzas, "\n\n")
+}
+
+// listingItem returns the listing entry around a clip link.
+func (s *JWScraper) listingItem(link *goquery.Selection) *goquery.Selection {
+	item := link.Closest(s.source.ListingItem)
+	if item.Length() == 0 {
+		item = link.Parent().Parent()
+	}
+	return item
+}
+
+// extractImageURLs collects the thumbnail renditions of a listing item,
+// ordered from the largest to the smallest.
+func (s *JWScraper) extractImageURLs(link *goquery.Selection) []string {
+	var urls []string
+	s.listingItem(link).Find(s.source.ListingImages).Each(func(i int, img *goquery.Selection) {
+		for _, attr := range s.source.ImageSizeAttributes {
+			if value, exists := img.Attr(attr); exists && value != "" {
+				urls = appendUnique(urls, value)
+			}
+		}
+		if value, exists := img.Attr("src"); exists && strings.HasPrefix(value, "http") {
+			urls = appendUnique(urls, value)
+		}
+	})
+
+	return urls
+}
+
+func appendUnique(values []string, value string) []string {
+	for _, existing := range values {
+		if existing == value {
+			return values
+		}
+	}
+	return append(values, value)
 }
 
 func (s *JWScraper) extractClipeID(url string) string {
-	parts := strings.Split(url, "/")
+	parts := strings.Split(strings.TrimSuffix(url, "/"), "/")
 	if len(parts) > 0 {
 		return parts[len(parts)-1]
 	}
 	return ""
 }
 
-func (s *JWScraper) extractYearFromTitle(titulo string) int {
-	re := regexp.MustCompile(`\b(202[0-9])\b`)
-	matches := re.FindStringSubmatch(titulo)
-	if len(matches) > 1 {
-		if year, err := strconv.Atoi(matches[1]); err == nil {
-			return year
+// parseAPITime reads the timestamps of the pub-media API, which come as
+// "2006-01-02 15:04:05" or RFC 3339.
+func parseAPITime(value string) time.Time {
+	for _, layout := range []string{time.RFC3339, "2006-01-02 15:04:05", "2006-01-02"} {
+		if parsed, err := time.Parse(layout, strings.TrimSpace(value)); err == nil {
+			return parsed
 		}
 	}
+	return time.Time{}
+}
 
-	re2 := regexp.MustCompile(`congresso de (\d{4})`)
-	matches2 := re2.FindStringSubmatch(titulo)
-	if len(matches2) > 1 {
-		if year, err := strconv.Atoi(matches2[1]); err == nil {
-			return year
-		}
+func (s *JWScraper) findAudioFileForClipe(clipe domain.ClipeMusical) (JWAudioFile, *apiIndex, bool, error) {
+	language := clipe.GetIdioma()
+	index, err := s.languageIndex(language)
+	if err != nil {
+		return JWAudioFile{}, nil, false, err
 	}
 
-	re3 := regexp.MustCompile(`de (\d{4})`)
-	matches3 := re3.FindStringSubmatch(titulo)
-	if len(matches3) > 1 {
-		if year, err := strconv.Atoi(matches3[1]); err == nil {
-			return year
+	if i, method, found := index.find(clipeIdentifiers(clipe), clipe.Titulo); found {
+		s.logger.Debug("Clipe associado ao arquivo da API", "titulo", clipe.Titulo, "metodo", method)
+		return index.files[i], index, true, nil
+	}
+
+	s.logger.Warn("URL de download não encontrada para clipe", "titulo", clipe.Titulo, "idioma", language)
+	return JWAudioFile{}, index, false, nil
+}
+
+// languageIndex returns the API files of a language, loading them on first
+// use. Concurrent callers wait for a single load.
+func (s *JWScraper) languageIndex(language string) (*apiIndex, error) {
+	s.cacheMu.Lock()
+	defer s.cacheMu.Unlock()
+
+	if index, loaded := s.downloadCache[language]; loaded {
+		return index, nil
+	}
+
+	index, err := s.loadAPIIndex(language)
+	if err != nil {
+		return nil, err
+	}
+	s.downloadCache[language] = index
+	return index, nil
+}
+
+func (s *JWScraper) loadAPIIndex(language string) (*apiIndex, error) {
+	apiResponse, err := s.fetchAPIResponse(language)
+	if err != nil {
+		return nil, err
+	}
+
+	pubCode := strings.TrimSpace(apiResponse.Pub)
+	if pubCode == "" {
+		pubCode = s.pub
+	}
+
+	var files []JWAudioFile
+	for langCode, langFiles := range apiResponse.Files {
+		preferred := s.preferredFiles(langFiles)
+		s.logger.Debug("Processando idioma", "lang", langCode, "arquivos", len(preferred))
+
+		for _, audioFile := range preferred {
+			if audioFile.File.URL != "" && audioFile.Title != "" {
+				files = append(files, audioFile)
+				s.logger.Debug("Link de download adicionado ao cache (API)",
+					"titulo", audioFile.Title,
+					"url", audioFile.File.URL,
+					"filesize", audioFile.FileSize)
+			}
 		}
 	}
 
-	return 0
+	index := newAPIIndex(pubCode, files)
+	index.pubName = strings.TrimSpace(apiResponse.PubName)
+	s.logger.Info("Cache de downloads carregado via API", "idioma", language, "total_links", len(files))
+	return index, nil
 }
 
-func (s *JWScraper) findDownloadURLForClipe(titulo string) (string, error) {
-	if url, exists := s.downloadCache[titulo]; exists {
-		return url, nil
+// UnmatchedRemoteFiles lists the API files no listing item was matched to
+// so far, for the languages already loaded.
+func (s *JWScraper) UnmatchedRemoteFiles() []domain.RemoteFile {
+	if s.mode == ScrapingModeAPI {
+		return nil // every API file is a catalog entry
 	}
 
-	if len(s.downloadCache) == 0 {
-		err := s.loadDownloadCache()
-		if err != nil {
-			return "", err
+	s.cacheMu.Lock()
+	defer s.cacheMu.Unlock()
+
+	var files []domain.RemoteFile
+	for language, index := range s.downloadCache {
+		for _, audioFile := range index.unmatched() {
+			files = append(files, domain.RemoteFile{
+				Titulo:      audioFile.Title,
+				URLDownload: audioFile.File.URL,
+				Size:        int64(audioFile.FileSize),
+				Checksum:    audioFile.File.Checksum,
+				Idioma:      language,
+			})
 		}
 	}
+	return files
+}
+
+func (s *JWScraper) FetchRemoteFiles() ([]domain.RemoteFile, error) {
+	var files []domain.RemoteFile
+
+	for _, language := range s.languages {
+		apiResponse, err := s.fetchAPIResponse(language)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, langFiles := range apiResponse.Files {
+			for _, audioFile := range s.preferredFiles(langFiles) {
+				if audioFile.File.URL == "" {
+					continue
+				}
 
-	if url, exists := s.downloadCache[titulo]; exists {
-		return url, nil
+				modifiedAt := parseAPITime(audioFile.File.ModifiedDatetime)
+				files = append(files, domain.RemoteFile{
+					Titulo:      audioFile.Title,
+					URLDownload: audioFile.File.URL,
+					Size:        int64(audioFile.FileSize),
+					Checksum:    audioFile.File.Checksum,
+					ModifiedAt:  modifiedAt,
+					Idioma:      language,
+				})
+			}
+		}
 	}
 
-	s.logger.Warn("URL de download não encontrada para clipe", "titulo", titulo)
-	return "", nil
+	return files, nil
 }
 
-func (s *JWScraper) loadDownloadCache() error {
-	s.logger.Info("Carregando cache de downloads via API JSON", "url", s.downloadURL)
+// jwLocale returns the locale segment a jw.org path starts with.
+func jwLocale(urlPath string) string {
+	parts := strings.SplitN(strings.TrimPrefix(urlPath, "/"), "/", 2)
+	if len(parts) < 2 {
+		return ""
+	}
+	return parts[0]
+}
+
+func (s *JWScraper) fetchAPIResponse(language string) (*JWAPIResponse, error) {
+	apiURL := expandURL(s.source.PubMediaURL, map[string]string{
+		"pub":      s.pub,
+		"formats":  strings.Join(s.formats, ","),
+		"language": language,
+	})
+	s.logger.Info("Carregando cache de downloads via API JSON", "url", apiURL)
 
-	req, err := http.NewRequest("GET", s.downloadURL, nil)
+	req, err := http.NewRequest("GET", apiURL, nil)
 	if err != nil {
-		return fmt.Errorf("erro ao criar requisição: %w", err)
+		return nil, fmt.Errorf("erro ao criar requisição: %w", err)
 	}
 
 	req.Header.Set("User-Agent", s.userAgent)
 	req.Header.Set("Accept", "application/json")
-	req.Header.Set("Accept-Language", "pt-BR,pt;q=0.9,en;q=0.8")
+	req.Header.Set("Accept-Language", s.acceptLanguage(language))
 
-	resp, err := s.client.Do(req)
+	resp, err := s.doRequest(req)
 	if err != nil {
-		return fmt.Errorf("erro ao fazer requisição: %w", err)
+		return nil, fmt.Errorf("erro ao fazer requisição: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("status code inválido: %d", resp.StatusCode)
-	}
-
-	var apiResponse JWAPIResponse
-	if err := json.NewDecoder(resp.Body).Decode(&apiResponse); err != nil {
-		return fmt.Errorf("erro ao decodificar JSON: %w", err)
+		return nil, fmt.Errorf("status code inválido: %d", resp.StatusCode)
 	}
 
-	foundLinks := 0
-
-	for langCode, langFiles := range apiResponse.Files {
-		s.logger.Debug("Processando idioma", "lang", langCode, "mp3_count", len(langFiles.MP3))
-
-		for _, audioFile := range langFiles.MP3 {
-			if audioFile.File.URL != "" && audioFile.Title != "" {
-				titulo := strings.TrimSpace(audioFile.Title)
-
-				titulo = strings.TrimPrefix(titulo, "Reproduzir")
-				titulo = strings.TrimSpace(titulo)
-
-				if titulo != "" && len(titulo) > 2 {
-					s.downloadCache[titulo] = audioFile.File.URL
-					s.logger.Debug("Link de download adicionado ao cache (API)",
-						"titulo", titulo,
-						"url", audioFile.File.URL,
-						"filesize", audioFile.FileSize)
-					foundLinks++
-				}
-			}
-		}
+	apiResponse, err := s.decodeAPIResponse(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao decodificar JSON: %w", err)
 	}
 
-	s.logger.Info("Cache de downloads carregado via API", "total_links", len(s.downloadCache))
-	return nil
+	return apiResponse, nil
 }
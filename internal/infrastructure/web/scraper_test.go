@@ -0,0 +1,185 @@
+package web
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sant0x00/downloader-music/internal/domain"
+	"github.com/sant0x00/downloader-music/pkg/logger"
+)
+
+// listingServer serves the listing fixtures of testdata by page number.
+// Pages listed in failing answer with an error instead.
+func listingServer(t *testing.T, pages map[string]string, failing ...string) (*httptest.Server, *int) {
+	t.Helper()
+
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		page := r.URL.Query().Get("page")
+		for _, failed := range failing {
+			if page == failed {
+				http.Error(w, "indisponível", http.StatusInternalServerError)
+				return
+			}
+		}
+
+		fixture, exists := pages[page]
+		if !exists || r.URL.Path != "/pt/clipes/" {
+			http.NotFound(w, r)
+			return
+		}
+		data, err := os.ReadFile(filepath.Join("testdata", fixture))
+		if err != nil {
+			t.Errorf("fixture %s: %v", fixture, err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write(data)
+	}))
+	t.Cleanup(server.Close)
+
+	return server, &requests
+}
+
+func newTestScraper(t *testing.T, server *httptest.Server) *JWScraper {
+	t.Helper()
+
+	log, err := logger.NewSimpleLogger("error", "")
+	if err != nil {
+		t.Fatalf("logger: %v", err)
+	}
+	scraper := NewJWScraper("teste", 0, log)
+	scraper.SetListingURL("T", server.URL+"/pt/clipes/")
+	return scraper
+}
+
+var paginatedListing = map[string]string{
+	"":  "listing_page1.html",
+	"2": "listing_page2.html",
+	"3": "listing_page3.html",
+}
+
+func clipeURLs(clipes []domain.ClipeMusical) []string {
+	var urls []string
+	for _, clipe := range clipes {
+		urls = append(urls, clipe.URL[strings.Index(clipe.URL, "/pt/"):])
+	}
+	return urls
+}
+
+func assertURLs(t *testing.T, clipes []domain.ClipeMusical, want ...string) {
+	t.Helper()
+	if got := clipeURLs(clipes); strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("clipes = %v, want %v", got, want)
+	}
+}
+
+func TestScrapListingFollowsNextAndLoadMore(t *testing.T) {
+	server, requests := listingServer(t, paginatedListing)
+	scraper := newTestScraper(t, server)
+
+	clipes, err := scraper.ScrapClipesList("T")
+	if err != nil {
+		t.Fatalf("ScrapClipesList: %v", err)
+	}
+
+	// Page 2 is reached through rel=next, page 3 through the load-more
+	// button; Coragem appears on both of the first pages.
+	assertURLs(t, clipes,
+		"/pt/clipes/alegria/",
+		"/pt/clipes/coragem/",
+		"/pt/clipes/esperanca/",
+		"/pt/clipes/gratidao/",
+	)
+	if *requests != 3 {
+		t.Errorf("requests = %d, want 3", *requests)
+	}
+
+	first := clipes[0]
+	if first.Titulo != "Alegria (2024)" || first.Ano != 2024 || first.ID != "alegria" || first.Idioma != "T" {
+		t.Errorf("first clip = %+v", first)
+	}
+	if len(first.Imagens) != 2 || first.Imagens[0] != "https://cdn.example/alegria_lg.jpg" {
+		t.Errorf("images = %v", first.Imagens)
+	}
+}
+
+func TestScrapListingPageCap(t *testing.T) {
+	server, requests := listingServer(t, paginatedListing)
+	scraper := newTestScraper(t, server)
+	scraper.SetMaxPages(2)
+
+	clipes, err := scraper.ScrapClipesList("T")
+	if !errors.Is(err, domain.ErrIncompleteCatalog) {
+		t.Fatalf("error = %v, want ErrIncompleteCatalog", err)
+	}
+	assertURLs(t, clipes, "/pt/clipes/alegria/", "/pt/clipes/coragem/", "/pt/clipes/esperanca/")
+	if *requests != 2 {
+		t.Errorf("requests = %d, want 2", *requests)
+	}
+}
+
+func TestScrapListingCapOnLastPageIsComplete(t *testing.T) {
+	server, _ := listingServer(t, paginatedListing)
+	scraper := newTestScraper(t, server)
+	scraper.SetMaxPages(3)
+
+	clipes, err := scraper.ScrapClipesList("T")
+	if err != nil {
+		t.Fatalf("ScrapClipesList: %v", err)
+	}
+	if len(clipes) != 4 {
+		t.Errorf("clipes = %v", clipeURLs(clipes))
+	}
+}
+
+func TestScrapListingFailedPage(t *testing.T) {
+	server, _ := listingServer(t, paginatedListing, "3")
+	scraper := newTestScraper(t, server)
+
+	clipes, err := scraper.ScrapClipesList("T")
+	if !errors.Is(err, domain.ErrIncompleteCatalog) {
+		t.Fatalf("error = %v, want ErrIncompleteCatalog", err)
+	}
+	if !strings.Contains(err.Error(), "página 3") {
+		t.Errorf("error = %v, want the failed page", err)
+	}
+	assertURLs(t, clipes, "/pt/clipes/alegria/", "/pt/clipes/coragem/", "/pt/clipes/esperanca/")
+}
+
+func TestScrapListingFailedFirstPage(t *testing.T) {
+	server, _ := listingServer(t, paginatedListing, "")
+	scraper := newTestScraper(t, server)
+
+	clipes, err := scraper.ScrapClipesList("T")
+	if err == nil || errors.Is(err, domain.ErrIncompleteCatalog) {
+		t.Fatalf("error = %v, want a plain failure", err)
+	}
+	if len(clipes) != 0 {
+		t.Errorf("clipes = %v, want none", clipeURLs(clipes))
+	}
+}
+
+func TestScrapListingStopsOnRepeatedPages(t *testing.T) {
+	server, requests := listingServer(t, map[string]string{
+		"":       "listing_repeat.html",
+		"repeat": "listing_repeat.html",
+	})
+	scraper := newTestScraper(t, server)
+
+	clipes, err := scraper.ScrapClipesList("T")
+	if err != nil {
+		t.Fatalf("ScrapClipesList: %v", err)
+	}
+	assertURLs(t, clipes, "/pt/clipes/alegria/")
+	if *requests != 2 {
+		t.Errorf("requests = %d, want 2", *requests)
+	}
+}
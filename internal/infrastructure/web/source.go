@@ -0,0 +1,379 @@
+package web
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	neturl "net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/andybalholm/cascadia"
+	"github.com/sant0x00/downloader-music/internal/domain"
+)
+
+// Source describes how the site is read: the selectors of its pages, the
+// URLs of its endpoints, the cleanup of its titles and the fields of the
+// pub-media JSON. It lets a site change be followed from the configuration.
+type Source struct {
+	// ListingLink finds the clip links of a listing page. {path} is the
+	// listing path without its locale, as clip pages live below it.
+	ListingLink string
+	// ListingItem is the element around a link holding its images and ids.
+	ListingItem   string
+	ListingImages string
+	NextPage      []string
+	Lyrics        []string
+	// Meta selectors of the clip page, first match wins.
+	Description []string
+	Images      []string
+	Duration    []string
+	Published   []string
+	// PubMediaURL takes {pub}, {formats} and {language}; FinderURL takes
+	// {language} and {query}.
+	PubMediaURL string
+	FinderURL   string
+	// TitleCleanup are regular expressions removed from the titles.
+	TitleCleanup []string
+	APIFields    APIFields
+	// Languages gives the locale and the listing page of each language.
+	Languages map[string]SourceLanguage
+	// ImageSizeAttributes hold the renditions of listing images, largest
+	// first.
+	ImageSizeAttributes []string
+	// RelatedArea is the part of a clip page searched for related songs,
+	// the whole body when it is missing. Links to publications starting
+	// with SongbookPub count, and so do mentions matching SongReference,
+	// whose first group is the song number.
+	RelatedArea   string
+	SongbookPub   string
+	SongReference string
+	// TrackQuery is the finder query of a publication track and takes
+	// {pub}, {track} and {media}. The media comes from TrackMedia by
+	// publication, DefaultTrackMedia otherwise.
+	TrackQuery        string
+	TrackMedia        map[string]string
+	DefaultTrackMedia string
+}
+
+// SourceLanguage is a language of the site.
+type SourceLanguage struct {
+	Locale     string
+	ListingURL string
+}
+
+// APIFields names the fields of the pub-media JSON. Nested fields are
+// separated by dots, e.g. file.url.
+type APIFields struct {
+	Pub      string
+	PubName  string
+	Files    string
+	Title    string
+	DocID    string
+	Track    string
+	URL      string
+	Modified string
+	Checksum string
+	FileSize string
+	Duration string
+	Image    string
+}
+
+// DefaultSource describes jw.org as of this release.
+func DefaultSource() Source {
+	return Source{
+		ListingLink:   "h2 a[href*='{path}']",
+		ListingItem:   ".synopsis",
+		ListingImages: "[data-img-size-lg], [data-img-size-md], [data-img-size-sm], img",
+		NextPage: []string{
+			"link[rel='next']",
+			"a[rel='next']",
+			".pagination a.next",
+			".pagination .iconNext",
+			"a.jsLoadMore",
+			"[data-load-more-url]",
+		},
+		Lyrics:      []string{"#article .bodyTxt", ".bodyTxt"},
+		Description: []string{"meta[property='og:description']", "meta[name='description']"},
+		Images:      []string{"meta[property='og:image']", "meta[property='og:image:url']", "meta[name='twitter:image']"},
+		Duration:    []string{"meta[property='video:duration']", "meta[property='og:video:duration']", "meta[itemprop='duration']"},
+		Published:   []string{"meta[property='article:published_time']", "meta[name='dcterms.date']", "meta[itemprop='datePublished']", "time[datetime]"},
+		PubMediaURL: "https://b.jw-cdn.org/apis/pub-media/GETPUBMEDIALINKS?output=json&pub={pub}&fileformat={formats}&alllangs=0&langwritten={language}&txtCMSLang={language}",
+		FinderURL:   "https://www.jw.org/finder?wtlocale={language}&{query}",
+		TitleCleanup: []string{
+			`^\s*Reproduzir\s*`,
+		},
+		APIFields: APIFields{
+			Pub:      "pub",
+			PubName:  "pubName",
+			Files:    "files",
+			Title:    "title",
+			DocID:    "docid",
+			Track:    "track",
+			URL:      "file.url",
+			Modified: "file.modifiedDatetime",
+			Checksum: "file.checksum",
+			FileSize: "filesize",
+			Duration: "duration",
+			Image:    "trackImage.url",
+		},
+		Languages: map[string]SourceLanguage{
+			"T": {Locale: "pt", ListingURL: "https://www.jw.org/pt/biblioteca/musica-canticos/clipes-musicais/"},
+			"E": {Locale: "en", ListingURL: "https://www.jw.org/en/library/music-songs/music-videos/"},
+			"S": {Locale: "es", ListingURL: "https://www.jw.org/es/biblioteca/musica-canciones/videos-musicales/"},
+		},
+		ImageSizeAttributes: []string{
+			"data-img-size-xl",
+			"data-img-size-lg",
+			"data-img-size-md",
+			"data-img-size-sm",
+			"data-img-size-xs",
+		},
+		RelatedArea: "#article",
+		SongbookPub: "sjj",
+		// "Cântico 151", "Song 12", "Canción n.º 3".
+		SongReference: `(?i)\b(?:cântico|cantico|song|canción|cancion)\s+(?:n\.?\s*[º°o]?\s*)?(\d{1,3})\b`,
+		TrackQuery:    "lank=pub-{pub}_{track}_{media}",
+		// The music videos are published as video first.
+		TrackMedia:        map[string]string{domain.DefaultPublication: "VIDEO"},
+		DefaultTrackMedia: "AUDIO",
+	}
+}
+
+// compiledSource holds the regular expressions of a validated source.
+type compiledSource struct {
+	titleCleanup  []*regexp.Regexp
+	songReference *regexp.Regexp
+}
+
+// SetSource replaces the description of the site after checking every
+// selector, URL template, cleanup rule and field name in it.
+func (s *JWScraper) SetSource(source Source) error {
+	compiled, err := source.validate()
+	if err != nil {
+		return err
+	}
+	s.source = source
+	s.compiled = compiled
+	return nil
+}
+
+func (source Source) validate() (compiledSource, error) {
+	var compiled compiledSource
+
+	selectors := []struct {
+		name   string
+		values []string
+	}{
+		{"listing_link", []string{strings.ReplaceAll(source.ListingLink, "{path}", "clipes")}},
+		{"listing_item", []string{source.ListingItem}},
+		{"listing_images", []string{source.ListingImages}},
+		{"next_page", source.NextPage},
+		{"lyrics", source.Lyrics},
+		{"description", source.Description},
+		{"images", source.Images},
+		{"duration", source.Duration},
+		{"published", source.Published},
+		{"related_area", []string{source.RelatedArea}},
+	}
+	for _, selector := range selectors {
+		if len(selector.values) == 0 {
+			return compiled, fmt.Errorf("seletor %s vazio", selector.name)
+		}
+		for _, value := range selector.values {
+			if _, err := cascadia.Compile(value); err != nil {
+				return compiled, fmt.Errorf("seletor %s inválido (%q): %w", selector.name, value, err)
+			}
+		}
+	}
+
+	if err := validateURLTemplate("pub_media_url", source.PubMediaURL, "{pub}", "{formats}", "{language}"); err != nil {
+		return compiled, err
+	}
+	if err := validateURLTemplate("finder_url", source.FinderURL, "{language}", "{query}"); err != nil {
+		return compiled, err
+	}
+	for code, language := range source.Languages {
+		if code == "" || language.Locale == "" {
+			return compiled, fmt.Errorf("idioma %q sem código ou locale", code)
+		}
+		if err := validateURLTemplate("do idioma "+code, language.ListingURL); err != nil {
+			return compiled, err
+		}
+	}
+
+	for _, pattern := range source.TitleCleanup {
+		rule, err := regexp.Compile(pattern)
+		if err != nil {
+			return compiled, fmt.Errorf("regra de limpeza de título inválida (%q): %w", pattern, err)
+		}
+		compiled.titleCleanup = append(compiled.titleCleanup, rule)
+	}
+
+	songReference, err := regexp.Compile(source.SongReference)
+	if err != nil || songReference.NumSubexp() < 1 {
+		return compiled, fmt.Errorf("song_reference inválida, precisa de um grupo com o número (%q)", source.SongReference)
+	}
+	compiled.songReference = songReference
+	if source.SongbookPub == "" {
+		return compiled, fmt.Errorf("songbook_pub vazio")
+	}
+
+	if len(source.ImageSizeAttributes) == 0 {
+		return compiled, fmt.Errorf("image_size_attributes vazio")
+	}
+	for _, attr := range source.ImageSizeAttributes {
+		if attr == "" || strings.ContainsAny(attr, " \t\"'=<>/") {
+			return compiled, fmt.Errorf("atributo de imagem inválido: %q", attr)
+		}
+	}
+
+	for _, placeholder := range []string{"{pub}", "{track}"} {
+		if !strings.Contains(source.TrackQuery, placeholder) {
+			return compiled, fmt.Errorf("track_query sem o marcador %s", placeholder)
+		}
+	}
+	if source.DefaultTrackMedia == "" {
+		return compiled, fmt.Errorf("default_track_media vazio")
+	}
+	for pub, media := range source.TrackMedia {
+		if pub == "" || media == "" {
+			return compiled, fmt.Errorf("track_media inválido para a publicação %q", pub)
+		}
+	}
+
+	fields := source.APIFields
+	for _, value := range []string{
+		fields.Pub, fields.PubName, fields.Files, fields.Title, fields.DocID, fields.Track,
+		fields.URL, fields.Modified, fields.Checksum, fields.FileSize, fields.Duration, fields.Image,
+	} {
+		if value == "" || strings.HasPrefix(value, ".") || strings.HasSuffix(value, ".") || strings.Contains(value, "..") {
+			return compiled, fmt.Errorf("campo da API inválido: %q", value)
+		}
+	}
+
+	return compiled, nil
+}
+
+// validateURLTemplate requires the placeholders and an absolute http(s) URL
+// once they are filled in.
+func validateURLTemplate(name, template string, placeholders ...string) error {
+	example := template
+	for _, placeholder := range placeholders {
+		if !strings.Contains(template, placeholder) {
+			return fmt.Errorf("URL %s sem o marcador %s", name, placeholder)
+		}
+		example = strings.ReplaceAll(example, placeholder, "x")
+	}
+
+	parsed, err := neturl.Parse(example)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return fmt.Errorf("URL %s inválida: %s", name, template)
+	}
+	return nil
+}
+
+// expandURL fills the placeholders of a URL template, which are escaped
+// unless they are a query of their own.
+func expandURL(template string, values map[string]string) string {
+	var pairs []string
+	for placeholder, value := range values {
+		if placeholder != "query" {
+			value = neturl.QueryEscape(value)
+		}
+		pairs = append(pairs, "{"+placeholder+"}", value)
+	}
+	return strings.NewReplacer(pairs...).Replace(template)
+}
+
+// cleanTitle applies the title cleanup rules of the source.
+func (s *JWScraper) cleanTitle(title string) string {
+	for _, rule := range s.compiled.titleCleanup {
+		title = rule.ReplaceAllString(title, "")
+	}
+	return strings.TrimSpace(title)
+}
+
+// decodeAPIResponse reads the pub-media JSON through the field names of the
+// source, so a renamed field only needs a configuration change.
+func (s *JWScraper) decodeAPIResponse(r io.Reader) (*JWAPIResponse, error) {
+	var raw map[string]any
+	if err := json.NewDecoder(r).Decode(&raw); err != nil {
+		return nil, err
+	}
+
+	fields := s.source.APIFields
+	response := &JWAPIResponse{
+		Pub:     jsonString(raw, fields.Pub),
+		PubName: jsonString(raw, fields.PubName),
+		Files:   make(map[string]JWLanguageFiles),
+	}
+
+	languages, _ := jsonField(raw, fields.Files).(map[string]any)
+	for language, value := range languages {
+		formats, _ := value.(map[string]any)
+		langFiles := make(JWLanguageFiles)
+		for format, list := range formats {
+			items, _ := list.([]any)
+			for _, item := range items {
+				file, ok := item.(map[string]any)
+				if !ok {
+					continue
+				}
+				langFiles[format] = append(langFiles[format], JWAudioFile{
+					Title:    s.cleanTitle(jsonString(file, fields.Title)),
+					Pub:      jsonString(file, fields.Pub),
+					DocID:    int(jsonNumber(file, fields.DocID)),
+					Track:    int(jsonNumber(file, fields.Track)),
+					FileSize: int(jsonNumber(file, fields.FileSize)),
+					Duration: jsonNumber(file, fields.Duration),
+					File: JWFile{
+						URL:              jsonString(file, fields.URL),
+						ModifiedDatetime: jsonString(file, fields.Modified),
+						Checksum:         jsonString(file, fields.Checksum),
+					},
+					TrackImage: JWImage{URL: jsonString(file, fields.Image)},
+				})
+			}
+		}
+		response.Files[language] = langFiles
+	}
+
+	return response, nil
+}
+
+// jsonField follows a dotted path through decoded JSON objects.
+func jsonField(object map[string]any, path string) any {
+	var value any = object
+	for _, key := range strings.Split(path, ".") {
+		current, ok := value.(map[string]any)
+		if !ok {
+			return nil
+		}
+		value = current[key]
+	}
+	return value
+}
+
+func jsonString(object map[string]any, path string) string {
+	switch value := jsonField(object, path).(type) {
+	case string:
+		return value
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	}
+	return ""
+}
+
+// jsonNumber also accepts numbers sent as strings.
+func jsonNumber(object map[string]any, path string) float64 {
+	switch value := jsonField(object, path).(type) {
+	case float64:
+		return value
+	case string:
+		number, _ := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		return number
+	}
+	return 0
+}
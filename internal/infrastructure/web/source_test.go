@@ -0,0 +1,60 @@
+package web
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSourceValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(*Source)
+		want   string
+	}{
+		{"default", func(*Source) {}, ""},
+		{"related area", func(s *Source) { s.RelatedArea = "#[" }, "related_area"},
+		{"song reference without group", func(s *Source) { s.SongReference = `cântico \d+` }, "song_reference"},
+		{"song reference invalid", func(s *Source) { s.SongReference = `(` }, "song_reference"},
+		{"songbook pub", func(s *Source) { s.SongbookPub = "" }, "songbook_pub"},
+		{"image attributes empty", func(s *Source) { s.ImageSizeAttributes = nil }, "image_size_attributes"},
+		{"image attribute", func(s *Source) { s.ImageSizeAttributes = []string{"data img"} }, "atributo de imagem"},
+		{"language listing", func(s *Source) { s.Languages["F"] = SourceLanguage{Locale: "fr", ListingURL: "/fr/"} }, "idioma F"},
+		{"language locale", func(s *Source) { s.Languages["F"] = SourceLanguage{ListingURL: "https://www.jw.org/fr/"} }, "locale"},
+		{"track query", func(s *Source) { s.TrackQuery = "lank=pub-{pub}" }, "{track}"},
+		{"track media", func(s *Source) { s.TrackMedia["osg"] = "" }, "track_media"},
+		{"default track media", func(s *Source) { s.DefaultTrackMedia = "" }, "default_track_media"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source := DefaultSource()
+			tt.change(&source)
+			_, err := source.validate()
+			switch {
+			case tt.want == "" && err != nil:
+				t.Errorf("validate() = %v, want no error", err)
+			case tt.want != "" && (err == nil || !strings.Contains(err.Error(), tt.want)):
+				t.Errorf("validate() = %v, want an error about %s", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestClipeFromAPITrackQuery(t *testing.T) {
+	scraper := NewJWScraper("teste", 0, nil)
+
+	clipe := scraper.clipeFromAPI("T", "", JWAudioFile{Title: "Alegria", Pub: "osg", Track: 12})
+	if !strings.HasSuffix(clipe.URL, "&lank=pub-osg_12_VIDEO") {
+		t.Errorf("URL = %s, want the video track query", clipe.URL)
+	}
+
+	source := DefaultSource()
+	source.TrackQuery = "pub={pub}&track={track}"
+	if err := scraper.SetSource(source); err != nil {
+		t.Fatalf("SetSource: %v", err)
+	}
+	clipe = scraper.clipeFromAPI("T", "", JWAudioFile{Title: "Coragem", Pub: "sjjm", Track: 3})
+	if !strings.HasSuffix(clipe.URL, "&pub=sjjm&track=3") {
+		t.Errorf("URL = %s, want the configured track query", clipe.URL)
+	}
+}
@@ -0,0 +1,220 @@
+package cli
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/sant0x00/downloader-music/internal/application"
+	"github.com/spf13/cobra"
+)
+
+func (c *CLI) catalogCommand() *cobra.Command {
+	catalogCmd := &cobra.Command{
+		Use:   "catalog",
+		Short: "Consulta o catálogo do jw.org",
+		Long:  "Lista e pesquisa o catálogo remoto, indicando o que já está na biblioteca",
+	}
+
+	catalogListCmd := &cobra.Command{
+		Use:   "list",
+		Short: "Lista o catálogo",
+		Long:  "Lista todos os clipes do catálogo com título, ano, tamanho, formato e status",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return c.catalog(cmd, "")
+		},
+	}
+
+	catalogSearchCmd := &cobra.Command{
+		Use:   "search [busca]",
+		Short: "Pesquisa o catálogo por título",
+		Long:  "Pesquisa o catálogo sem diferenciar maiúsculas, acentos ou pequenos erros de digitação",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return c.catalog(cmd, args[0])
+		},
+	}
+
+	for _, cmd := range []*cobra.Command{catalogListCmd, catalogSearchCmd} {
+		cmd.Flags().Int("year-from", 0, "Ano inicial")
+		cmd.Flags().Int("year-to", 0, "Ano final")
+		cmd.Flags().String("status", "all", "Status: all, downloaded ou missing")
+		cmd.Flags().StringSlice("category", nil, "Apenas as categorias informadas, ex.: congresso")
+		cmd.Flags().String("sort", "relevance", "Ordenação: relevance, title, year ou size")
+		cmd.Flags().StringP("output", "o", "table", "Saída: table, json ou csv")
+		cmd.Flags().String("lang", "", "Idiomas a considerar, ex.: T,E (padrão: os da configuração)")
+	}
+
+	catalogCmd.AddCommand(catalogListCmd, catalogSearchCmd)
+	return catalogCmd
+}
+
+func (c *CLI) catalog(cmd *cobra.Command, query string) error {
+	output, _ := cmd.Flags().GetString("output")
+	output = strings.ToLower(output)
+	if output != "table" && output != "json" && output != "csv" {
+		err := fmt.Errorf("saída inválida: %s (use table, json ou csv)", output)
+		fmt.Printf("❌ Erro: %v\n", err)
+		return err
+	}
+
+	filter := application.CatalogFilter{}
+	filter.YearFrom, _ = cmd.Flags().GetInt("year-from")
+	filter.YearTo, _ = cmd.Flags().GetInt("year-to")
+	statusName, _ := cmd.Flags().GetString("status")
+	status, err := application.ParseCatalogStatus(statusName)
+	if err != nil {
+		fmt.Printf("❌ Erro: %v\n", err)
+		return err
+	}
+	filter.Status = status
+	filter.Categorias, _ = cmd.Flags().GetStringSlice("category")
+
+	sortName, _ := cmd.Flags().GetString("sort")
+	sortBy, err := application.ParseCatalogSort(sortName)
+	if err != nil {
+		fmt.Printf("❌ Erro: %v\n", err)
+		return err
+	}
+
+	idiomas, err := c.selectedLanguages(cmd)
+	if err != nil {
+		return err
+	}
+
+	items, err := c.downloadService.Catalog(idiomas, query, filter)
+	if err != nil {
+		fmt.Printf("❌ Erro: %v\n", err)
+		return err
+	}
+	application.SortCatalog(items, sortBy)
+
+	switch output {
+	case "json":
+		return writeCatalogJSON(os.Stdout, items)
+	case "csv":
+		return writeCatalogCSV(os.Stdout, items)
+	}
+
+	if len(items) == 0 {
+		fmt.Println("Nenhum clipe encontrado.")
+		return nil
+	}
+	writeCatalogTable(os.Stdout, items, len(idiomas) > 1)
+	return nil
+}
+
+// catalogRow is the machine readable form of a catalog item.
+type catalogRow struct {
+	Titulo    string  `json:"titulo"`
+	Ano       int     `json:"ano,omitempty"`
+	Tamanho   int64   `json:"tamanho,omitempty"`
+	Formato   string  `json:"formato,omitempty"`
+	Duracao   float64 `json:"duracao,omitempty"`
+	Idioma    string  `json:"idioma"`
+	Categoria string  `json:"categoria,omitempty"`
+	Baixado   bool    `json:"baixado"`
+	URL       string  `json:"url"`
+	Arquivo   string  `json:"arquivo"`
+}
+
+func newCatalogRow(item application.CatalogItem) catalogRow {
+	return catalogRow{
+		Titulo:    item.Clipe.Titulo,
+		Ano:       item.Clipe.Ano,
+		Tamanho:   item.Clipe.TamanhoArquivo,
+		Formato:   item.Formato(),
+		Duracao:   item.Clipe.Duracao,
+		Idioma:    item.Clipe.GetIdioma(),
+		Categoria: item.Clipe.Categoria,
+		Baixado:   item.Baixado,
+		URL:       item.Clipe.URL,
+		Arquivo:   item.Clipe.GetRelativePath(),
+	}
+}
+
+func writeCatalogJSON(w io.Writer, items []application.CatalogItem) error {
+	rows := make([]catalogRow, 0, len(items))
+	for _, item := range items {
+		rows = append(rows, newCatalogRow(item))
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(rows)
+}
+
+func writeCatalogCSV(w io.Writer, items []application.CatalogItem) error {
+	writer := csv.NewWriter(w)
+	writer.Write([]string{"titulo", "ano", "tamanho", "formato", "duracao", "idioma", "categoria", "baixado", "url", "arquivo"})
+	for _, item := range items {
+		row := newCatalogRow(item)
+		writer.Write([]string{
+			row.Titulo,
+			strconv.Itoa(row.Ano),
+			strconv.FormatInt(row.Tamanho, 10),
+			row.Formato,
+			strconv.FormatFloat(row.Duracao, 'f', 1, 64),
+			row.Idioma,
+			row.Categoria,
+			strconv.FormatBool(row.Baixado),
+			row.URL,
+			row.Arquivo,
+		})
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+func writeCatalogTable(w io.Writer, items []application.CatalogItem, showLanguage bool) {
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	header := "TÍTULO\tANO\tCATEGORIA\tTAMANHO\tFORMATO\tSTATUS"
+	if showLanguage {
+		header += "\tIDIOMA"
+	}
+	fmt.Fprintln(table, header)
+
+	downloaded := 0
+	for _, item := range items {
+		ano, categoria, formato, status := "-", "-", "-", "⬜ pendente"
+		if item.Clipe.Ano > 0 {
+			ano = strconv.Itoa(item.Clipe.Ano)
+		}
+		if item.Clipe.Categoria != "" {
+			categoria = item.Clipe.Categoria
+		}
+		if item.Formato() != "" {
+			formato = item.Formato()
+		}
+		if item.Baixado {
+			status = "✅ baixado"
+			downloaded++
+		}
+
+		line := fmt.Sprintf("%s\t%s\t%s\t%s\t%s\t%s", item.Clipe.Titulo, ano, categoria, formatSize(item.Clipe.TamanhoArquivo), formato, status)
+		if showLanguage {
+			line += "\t" + item.Clipe.GetIdioma()
+		}
+		fmt.Fprintln(table, line)
+	}
+	table.Flush()
+
+	fmt.Fprintf(w, "\n📋 %d clipes, %d baixados, %d pendentes\n", len(items), downloaded, len(items)-downloaded)
+}
+
+func formatSize(bytes int64) string {
+	switch {
+	case bytes <= 0:
+		return "-"
+	case bytes < 1024*1024:
+		return fmt.Sprintf("%.0f KB", float64(bytes)/1024)
+	default:
+		return fmt.Sprintf("%.1f MB", float64(bytes)/(1024*1024))
+	}
+}
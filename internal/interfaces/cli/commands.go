@@ -1,12 +1,18 @@
 package cli
 
 import (
+	"bufio"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/sant0x00/downloader-music/internal/application"
 	"github.com/sant0x00/downloader-music/internal/domain"
+	"github.com/sant0x00/downloader-music/internal/infrastructure/audio"
 	"github.com/sant0x00/downloader-music/internal/infrastructure/config"
 	"github.com/sant0x00/downloader-music/internal/infrastructure/download"
 	"github.com/sant0x00/downloader-music/internal/infrastructure/storage"
@@ -39,8 +45,21 @@ type CLI struct {
 	config          *config.Config
 	logger          domain.Logger
 	downloadService *application.DownloadService
+	libraryService  *application.LibraryService
+	tagger          *audio.ID3Tagger
+	scraper         *web.JWScraper
+	languages       []string
+	publications    map[string]config.PublicationConfig
+	// publication is the one being downloaded, shown in the output.
+	publication string
+	// offline answers every scraper request from the cache.
+	offline bool
 }
 
+// errOfflineDownload is returned by the commands that would download files
+// while offline.
+var errOfflineDownload = errors.New("o modo offline só permite consultas e simulações (--dry-run)")
+
 func NewCLI() (*CLI, error) {
 	configPath := filepath.Join("configs", "config.yaml")
 	cfg, err := config.LoadConfig(configPath)
@@ -53,8 +72,74 @@ func NewCLI() (*CLI, error) {
 		return nil, fmt.Errorf("erro ao criar logger: %w", err)
 	}
 
-	repository := storage.NewFileSystemRepository(cfg.Download.OutputDirectory, log)
+	filenameStyle, err := domain.ParseFilenameStyle(cfg.Download.FilenameStyle)
+	if err != nil {
+		return nil, fmt.Errorf("erro na configuração: %w", err)
+	}
+
+	yearSource, err := domain.ParseYearSource(cfg.Download.YearSource)
+	if err != nil {
+		return nil, fmt.Errorf("erro na configuração: %w", err)
+	}
+
+	var categoryRules []domain.CategoryRule
+	for _, category := range cfg.Categories {
+		rule, err := domain.NewCategoryRule(category.Name, category.Title, category.URL, category.Publication)
+		if err != nil {
+			return nil, fmt.Errorf("erro na configuração: %w", err)
+		}
+		categoryRules = append(categoryRules, rule)
+	}
+	categoryRules = append(categoryRules, domain.DefaultCategoryRules...)
+
+	languages, err := domain.ParseLanguages(cfg.Scraping.Languages)
+	if err != nil {
+		return nil, fmt.Errorf("erro na configuração: %w", err)
+	}
+	languageMode, err := application.ParseLanguageMode(cfg.Scraping.LanguageMode)
+	if err != nil {
+		return nil, fmt.Errorf("erro na configuração: %w", err)
+	}
+	if languageMode == application.LanguageModePair && len(languages) < 2 {
+		return nil, fmt.Errorf("erro na configuração: o modo pair exige dois idiomas")
+	}
+	scrapingMode, err := web.ParseScrapingMode(cfg.Scraping.Mode)
+	if err != nil {
+		return nil, fmt.Errorf("erro na configuração: %w", err)
+	}
+	publications, err := parsePublications(cfg.Publications)
+	if err != nil {
+		return nil, fmt.Errorf("erro na configuração: %w", err)
+	}
+
+	repository, err := newRepository(cfg, log)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao configurar armazenamento: %w", err)
+	}
 	scraper := web.NewJWScraper(cfg.Scraping.UserAgent, cfg.Scraping.DelayBetweenRequests, log)
+	scraper.SetLanguages(languages)
+	scraper.SetMode(scrapingMode, cfg.Scraping.EnrichFromHTML)
+	scraper.SetMaxPages(cfg.Scraping.MaxPages)
+	scraper.SetDetailPages(cfg.Scraping.DetailPages)
+	if cfg.Cache.Enabled {
+		scraper.SetCache(cfg.Cache.Directory, cfg.Cache.TTL)
+	}
+	source, err := scrapingSource(cfg.Scraping.Source)
+	if err != nil {
+		return nil, fmt.Errorf("erro na configuração: %w", err)
+	}
+	if err := scraper.SetSource(source); err != nil {
+		return nil, fmt.Errorf("erro na configuração: scraping.source: %w", err)
+	}
+	if cfg.Scraping.RequestsPerSecond > 0 {
+		scraper.SetRateLimit(cfg.Scraping.RequestsPerSecond, cfg.Scraping.Burst)
+	}
+	if cfg.Scraping.BaseURL != "" {
+		scraper.SetListingURL(domain.DefaultLanguage, cfg.Scraping.BaseURL)
+	}
+	for code, listingURL := range cfg.Scraping.ListingURLs {
+		scraper.SetListingURL(strings.ToUpper(code), listingURL)
+	}
 	downloader := download.NewHTTPDownloader(
 		repository,
 		log,
@@ -63,15 +148,222 @@ func NewCLI() (*CLI, error) {
 		cfg.Download.TimeoutSeconds,
 	)
 
+	tagger := audio.NewID3Tagger(audio.TagTemplates{
+		Title:    cfg.Tags.Title,
+		Artist:   cfg.Tags.Artist,
+		Album:    cfg.Tags.Album,
+		Genre:    cfg.Tags.Genre,
+		Language: cfg.Tags.Language,
+		Comment:  cfg.Tags.Comment,
+	})
+	var covers *download.CoverFetcher
+	if cfg.Cover.Enabled {
+		covers = download.NewCoverFetcher(cfg.Scraping.UserAgent, cfg.Cover.MaxBytes, cfg.Download.TimeoutSeconds, log)
+		if cfg.Cover.Embed {
+			tagger.SetCoverSource(covers, log)
+		}
+	}
+	if cfg.Lyrics.Enabled && cfg.Lyrics.Embed {
+		tagger.SetLyricsSource(scraper, log)
+	}
+	if cfg.Tags.Enabled {
+		downloader.SetTagger(tagger)
+	}
+	inspector := audio.NewMP3Analyzer()
+	downloader.SetInspector(inspector)
+
 	downloadService := application.NewDownloadService(scraper, downloader, repository, log)
+	downloadService.SetFilenameStyle(filenameStyle)
+	downloadService.SetYearSource(yearSource)
+	downloadService.SetCategoryRules(categoryRules)
+	downloadService.SetFolderLayout(cfg.Download.FolderLayout)
+	downloadService.SetDetailWorkers(cfg.Scraping.DetailWorkers)
+	downloadService.SetLanguageMode(languageMode)
+	if pub, exists := publications[domain.DefaultPublication]; exists {
+		scraper.SetPublication(pub.Code, pub.Formats)
+		downloadService.SetPublication(pub.Code, pub.Folder)
+	}
+
+	libraryService := application.NewLibraryService(repository, downloader, scraper, log)
+	libraryService.SetInspector(inspector)
+	if cfg.Lyrics.Enabled && cfg.Lyrics.Sidecar {
+		libraryService.SetLyricsSource(scraper)
+	}
+	if covers != nil {
+		libraryService.SetCoverSource(covers, cfg.Cover.FolderImage)
+	}
+	if cfg.Playlists.Enabled {
+		var formats []application.PlaylistFormat
+		for _, value := range cfg.Playlists.Formats {
+			format, err := application.ParsePlaylistFormat(value)
+			if err != nil {
+				return nil, fmt.Errorf("erro na configuração: %w", err)
+			}
+			formats = append(formats, format)
+		}
+		options := application.PlaylistOptions{
+			Formats:  formats,
+			PerYear:  cfg.Playlists.PerYear,
+			Library:  cfg.Playlists.Library,
+			NewInRun: cfg.Playlists.NewInRun,
+		}
+		if languageMode == application.LanguageModePair {
+			options.PairLanguages = languages[:2]
+		}
+		libraryService.SetPlaylistOptions(options)
+	}
 
 	return &CLI{
 		config:          cfg,
 		logger:          log,
 		downloadService: downloadService,
+		libraryService:  libraryService,
+		tagger:          tagger,
+		scraper:         scraper,
+		languages:       languages,
+		publications:    publications,
+		publication:     domain.DefaultPublication,
 	}, nil
 }
 
+// parsePublications validates the configured publications, indexed by code.
+// Each one lives in a folder named after it, except the music videos, which
+// keep the library root.
+func parsePublications(configured []config.PublicationConfig) (map[string]config.PublicationConfig, error) {
+	publications := make(map[string]config.PublicationConfig, len(configured))
+	for _, pub := range configured {
+		code, err := domain.ParsePublicationCode(pub.Code)
+		if err != nil {
+			return nil, err
+		}
+		formats, err := domain.ParseAudioFormats(pub.Formats)
+		if err != nil {
+			return nil, fmt.Errorf("publicação %s: %w", code, err)
+		}
+
+		pub.Code = code
+		pub.Formats = formats
+		if pub.Folder == "" && code != domain.DefaultPublication {
+			pub.Folder = code
+		}
+		publications[code] = pub
+	}
+	return publications, nil
+}
+
+// scrapingSource lays the configured selectors, URLs and fields over the
+// built-in description of the site. The scraper validates the result.
+func scrapingSource(configured config.SourceConfig) (web.Source, error) {
+	source := web.DefaultSource()
+
+	for _, field := range []struct {
+		value  string
+		target *string
+	}{
+		{configured.ListingLink, &source.ListingLink},
+		{configured.ListingItem, &source.ListingItem},
+		{configured.ListingImages, &source.ListingImages},
+		{configured.PubMediaURL, &source.PubMediaURL},
+		{configured.FinderURL, &source.FinderURL},
+		{configured.RelatedArea, &source.RelatedArea},
+		{configured.SongbookPub, &source.SongbookPub},
+		{configured.SongReference, &source.SongReference},
+		{configured.TrackQuery, &source.TrackQuery},
+		{configured.DefaultTrackMedia, &source.DefaultTrackMedia},
+	} {
+		if field.value != "" {
+			*field.target = field.value
+		}
+	}
+	for _, list := range []struct {
+		value  []string
+		target *[]string
+	}{
+		{configured.NextPage, &source.NextPage},
+		{configured.Lyrics, &source.Lyrics},
+		{configured.Description, &source.Description},
+		{configured.Images, &source.Images},
+		{configured.Duration, &source.Duration},
+		{configured.Published, &source.Published},
+		{configured.TitleCleanup, &source.TitleCleanup},
+		{configured.ImageSizeAttributes, &source.ImageSizeAttributes},
+	} {
+		if len(list.value) > 0 {
+			*list.target = list.value
+		}
+	}
+
+	fields := map[string]*string{
+		"pub":      &source.APIFields.Pub,
+		"pub_name": &source.APIFields.PubName,
+		"files":    &source.APIFields.Files,
+		"title":    &source.APIFields.Title,
+		"docid":    &source.APIFields.DocID,
+		"track":    &source.APIFields.Track,
+		"url":      &source.APIFields.URL,
+		"modified": &source.APIFields.Modified,
+		"checksum": &source.APIFields.Checksum,
+		"filesize": &source.APIFields.FileSize,
+		"duration": &source.APIFields.Duration,
+		"image":    &source.APIFields.Image,
+	}
+	for name, value := range configured.APIFields {
+		target, exists := fields[name]
+		if !exists {
+			return source, fmt.Errorf("campo da API desconhecido: %s", name)
+		}
+		*target = value
+	}
+
+	for code, configuredLanguage := range configured.Languages {
+		language := source.Languages[code]
+		if configuredLanguage.Locale != "" {
+			language.Locale = configuredLanguage.Locale
+		}
+		if configuredLanguage.ListingURL != "" {
+			language.ListingURL = configuredLanguage.ListingURL
+		}
+		source.Languages[code] = language
+	}
+	for pub, media := range configured.TrackMedia {
+		source.TrackMedia[pub] = media
+	}
+
+	return source, nil
+}
+
+func newRepository(cfg *config.Config, log domain.Logger) (domain.LibraryStorage, error) {
+	switch cfg.Storage.Backend {
+	case "", "filesystem":
+		return storage.NewFileSystemRepository(cfg.Download.OutputDirectory, log), nil
+	case "s3":
+		// Credentials are better kept out of the config file. They are read
+		// here only, so saving the config never writes them to disk.
+		accessKeyID := cfg.Storage.S3.AccessKeyID
+		if accessKeyID == "" {
+			accessKeyID = os.Getenv("AWS_ACCESS_KEY_ID")
+		}
+		secretAccessKey := cfg.Storage.S3.SecretAccessKey
+		if secretAccessKey == "" {
+			secretAccessKey = os.Getenv("AWS_SECRET_ACCESS_KEY")
+		}
+
+		return storage.NewS3Repository(storage.S3Options{
+			Endpoint:        cfg.Storage.S3.Endpoint,
+			Region:          cfg.Storage.S3.Region,
+			Bucket:          cfg.Storage.S3.Bucket,
+			Prefix:          cfg.Storage.S3.Prefix,
+			AccessKeyID:     accessKeyID,
+			SecretAccessKey: secretAccessKey,
+			UsePathStyle:    cfg.Storage.S3.UsePathStyle,
+			PartSize:        int64(cfg.Storage.S3.PartSizeMB) * 1024 * 1024,
+			Timeout:         time.Duration(cfg.Download.TimeoutSeconds) * time.Second,
+		}, log)
+	default:
+		return nil, fmt.Errorf("backend de armazenamento desconhecido: %s", cfg.Storage.Backend)
+	}
+}
+
 func (c *CLI) Execute() error {
 	rootCmd := &cobra.Command{
 		Use:   "downloader-music",
@@ -84,6 +376,18 @@ em português do site oficial das Testemunhas de Jeová (jw.org).
 
 A aplicação organiza os arquivos em pastas por ano e mantém os 
 nomes de arquivos limpos e organizados.`,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			offline, _ := cmd.Flags().GetBool("offline")
+			if !offline {
+				return nil
+			}
+			if !c.config.Cache.Enabled {
+				return fmt.Errorf("o modo offline exige o cache HTTP (cache.enabled)")
+			}
+			c.offline = true
+			c.scraper.SetOffline(true)
+			return nil
+		},
 		Run: func(cmd *cobra.Command, args []string) {
 			showBanner()
 			fmt.Println("Use 'downloader-music --help' para ver os comandos disponíveis.")
@@ -102,17 +406,47 @@ nomes de arquivos limpos e organizados.`,
 		Short: "Baixa todos os clipes disponíveis",
 		Long:  "Baixa todos os clipes musicais disponíveis na página de clipes do jw.org",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return c.downloadAll()
+			prune, _ := cmd.Flags().GetBool("prune")
+			force, _ := cmd.Flags().GetBool("force")
+			dryRun, _ := cmd.Flags().GetBool("dry-run")
+			idiomas, err := c.selectedLanguages(cmd)
+			if err != nil {
+				return err
+			}
+			return c.downloadAll(idiomas, prune, force, dryRun)
 		},
 	}
 
 	downloadTitleCmd := &cobra.Command{
 		Use:   "title [título]",
 		Short: "Baixa um clipe específico por título",
-		Long:  "Baixa um clipe musical específico procurando pelo título exato",
+		Long:  "Baixa um clipe musical específico procurando pelo título, sem diferenciar maiúsculas, acentos ou pequenos erros de digitação",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			idiomas, err := c.selectedLanguages(cmd)
+			if err != nil {
+				return err
+			}
+			first, _ := cmd.Flags().GetBool("first")
+			all, _ := cmd.Flags().GetBool("all")
+			return c.downloadSpecific(idiomas, args[0], first, all)
+		},
+	}
+
+	downloadPubCmd := &cobra.Command{
+		Use:   "pub [código]",
+		Short: "Baixa os áudios de uma publicação",
+		Long:  "Baixa todos os arquivos de áudio de uma publicação do jw.org, como sjjm, cada uma em sua própria pasta",
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return c.downloadSpecific(args[0])
+			prune, _ := cmd.Flags().GetBool("prune")
+			force, _ := cmd.Flags().GetBool("force")
+			dryRun, _ := cmd.Flags().GetBool("dry-run")
+			idiomas, err := c.selectedLanguages(cmd)
+			if err != nil {
+				return err
+			}
+			return c.downloadPublication(args[0], idiomas, prune, force, dryRun)
 		},
 	}
 
@@ -121,7 +455,11 @@ nomes de arquivos limpos e organizados.`,
 		Short: "Verifica novos clipes disponíveis",
 		Long:  "Verifica se há novos clipes disponíveis sem fazer download",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return c.checkNewClipes()
+			idiomas, err := c.selectedLanguages(cmd)
+			if err != nil {
+				return err
+			}
+			return c.checkNewClipes(idiomas)
 		},
 	}
 
@@ -141,56 +479,365 @@ nomes de arquivos limpos e organizados.`,
 		},
 	}
 
+	libraryCmd := &cobra.Command{
+		Use:   "library",
+		Short: "Gerencia a biblioteca local",
+		Long:  "Gerencia os arquivos já baixados na biblioteca local",
+	}
+
+	libraryDedupeCmd := &cobra.Command{
+		Use:   "dedupe",
+		Short: "Encontra e trata arquivos duplicados",
+		Long:  "Calcula o hash dos arquivos, agrupa conteúdos idênticos e aplica a política de deduplicação",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			policy, _ := cmd.Flags().GetString("policy")
+			return c.libraryDedupe(policy)
+		},
+	}
+
+	libraryVerifyCmd := &cobra.Command{
+		Use:   "verify",
+		Short: "Verifica a integridade dos arquivos baixados",
+		Long:  "Recalcula o hash de todos os arquivos e compara com o manifesto e com os checksums e tamanhos da API",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			_, err := c.libraryVerify()
+			return err
+		},
+	}
+
+	libraryRepairCmd := &cobra.Command{
+		Use:   "repair",
+		Short: "Baixa novamente os arquivos com problemas",
+		Long:  "Executa a verificação e baixa novamente os arquivos ausentes, truncados ou corrompidos",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return c.libraryRepair()
+		},
+	}
+
+	libraryExportCmd := &cobra.Command{
+		Use:   "export",
+		Short: "Exporta clipes para um arquivo zip ou tar.gz",
+		Long:  "Empacota os clipes selecionados, seus arquivos auxiliares e as entradas do manifesto em um único arquivo para uso offline",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return c.libraryExport(cmd)
+		},
+	}
+
+	libraryImportCmd := &cobra.Command{
+		Use:   "import [arquivo]",
+		Short: "Importa clipes de um arquivo exportado",
+		Long:  "Mescla um arquivo criado por 'library export' na biblioteca, verificando checksums e ignorando o que já existe",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return c.libraryImport(args[0])
+		},
+	}
+
+	libraryRetagCmd := &cobra.Command{
+		Use:   "retag",
+		Short: "Reaplica as tags ID3 nos arquivos existentes",
+		Long:  "Grava novamente as tags ID3v2.4 de todos os arquivos da biblioteca usando as regras atuais da configuração",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return c.libraryRetag()
+		},
+	}
+
 	downloadAllCmd.Flags().BoolP("verbose", "v", false, "Modo verboso")
+	downloadAllCmd.Flags().Bool("prune", false, "Arquiva ou remove arquivos locais que não existem mais no catálogo")
+	downloadAllCmd.Flags().Bool("force", false, "Limpa mesmo com o catálogo incompleto ou muito menor que a biblioteca")
+	downloadAllCmd.Flags().Bool("dry-run", false, "Apenas mostra o que seria feito, sem baixar nem remover arquivos")
 	downloadTitleCmd.Flags().BoolP("verbose", "v", false, "Modo verboso")
+	downloadTitleCmd.Flags().Bool("first", false, "Baixa o clipe mais parecido sem perguntar")
+	downloadTitleCmd.Flags().Bool("all", false, "Baixa todos os clipes encontrados sem perguntar")
+	downloadPubCmd.Flags().Bool("prune", false, "Arquiva ou remove arquivos locais que não existem mais na publicação")
+	downloadPubCmd.Flags().Bool("force", false, "Limpa mesmo com o catálogo incompleto ou muito menor que a biblioteca")
+	downloadPubCmd.Flags().Bool("dry-run", false, "Apenas mostra o que seria feito, sem baixar nem remover arquivos")
 	checkCmd.Flags().Bool("dry-run", true, "Apenas verificar sem baixar (sempre ativo neste comando)")
+	downloadCmd.PersistentFlags().String("lang", "", "Idiomas a considerar, ex.: T,E (padrão: os da configuração)")
+	checkCmd.Flags().String("lang", "", "Idiomas a considerar, ex.: T,E (padrão: os da configuração)")
+	libraryDedupeCmd.Flags().String("policy", c.config.Library.DedupePolicy, "Política: report, hardlink ou remove")
 
-	downloadCmd.AddCommand(downloadAllCmd, downloadTitleCmd)
+	rootCmd.PersistentFlags().Bool("offline", false, "Usa apenas o cache das páginas e da API, sem acessar o jw.org")
+	downloadCmd.AddCommand(downloadAllCmd, downloadTitleCmd, downloadPubCmd)
 	configCmd.AddCommand(configOutputCmd)
-	rootCmd.AddCommand(downloadCmd, checkCmd, configCmd)
+	libraryExportCmd.Flags().String("format", "zip", "Formato: zip ou tar.gz")
+	libraryExportCmd.Flags().StringP("output", "o", "", "Arquivo de saída (padrão: clipes-<data>.<formato>)")
+	libraryExportCmd.Flags().Int("year-from", 0, "Ano inicial")
+	libraryExportCmd.Flags().Int("year-to", 0, "Ano final")
+	libraryExportCmd.Flags().String("since", "", "Baixados a partir de (AAAA-MM-DD)")
+	libraryExportCmd.Flags().String("until", "", "Baixados até (AAAA-MM-DD)")
+	libraryExportCmd.Flags().String("lang", "", "Apenas os idiomas informados, ex.: T,E")
+	libraryExportCmd.Flags().StringSlice("category", nil, "Apenas as categorias informadas, ex.: congresso")
+
+	libraryCmd.AddCommand(libraryDedupeCmd, libraryVerifyCmd, libraryRepairCmd, libraryExportCmd, libraryImportCmd, libraryRetagCmd)
+	rootCmd.AddCommand(downloadCmd, checkCmd, c.catalogCommand(), configCmd, libraryCmd)
 
 	return rootCmd.Execute()
 }
 
-func (c *CLI) downloadAll() error {
+// selectedLanguages returns the languages given with --lang, or the
+// configured ones.
+func (c *CLI) selectedLanguages(cmd *cobra.Command) ([]string, error) {
+	value, _ := cmd.Flags().GetString("lang")
+	if value == "" {
+		return c.languages, nil
+	}
+
+	idiomas, err := domain.ParseLanguages([]string{value})
+	if err != nil {
+		fmt.Printf("❌ Erro: %v\n", err)
+		return nil, err
+	}
+	return idiomas, nil
+}
+
+func (c *CLI) downloadAll(idiomas []string, prune, force, dryRun bool) error {
 	showSmallBanner()
 	fmt.Println("🎵 Iniciando download de todos os clipes musicais...")
 	fmt.Printf("📁 Diretório de saída: %s\n", c.config.Download.OutputDirectory)
 	fmt.Printf("👥 Workers concorrentes: %d\n", c.config.Download.ConcurrentWorkers)
+	fmt.Printf("🌐 Idiomas: %s\n", strings.Join(idiomas, ", "))
+	if c.publication != domain.DefaultPublication {
+		fmt.Printf("📚 Publicação: %s\n", c.publication)
+	}
+	if dryRun {
+		fmt.Println("🧪 Modo simulação: nenhum arquivo será alterado")
+	}
+	if c.offline {
+		fmt.Println("📴 Modo offline: usando apenas o cache")
+	}
 	fmt.Println()
 
-	err := c.downloadService.DownloadAllClipes(c.config.Scraping.BaseURL)
+	if c.offline && !dryRun {
+		fmt.Printf("❌ Erro: %v\n", errOfflineDownload)
+		return errOfflineDownload
+	}
+
+	var prunePolicy application.PrunePolicy
+	if prune {
+		var err error
+		prunePolicy, err = application.ParsePrunePolicy(c.config.Library.PrunePolicy)
+		if err != nil {
+			fmt.Printf("❌ Erro: %v\n", err)
+			return err
+		}
+	}
+
+	runStart := time.Now()
+	catalog, err := c.downloadService.DownloadAllClipes(idiomas, dryRun)
 	if err != nil {
 		fmt.Printf("❌ Erro: %v\n", err)
 		return err
 	}
 
+	if prune {
+		actions, err := c.libraryService.Prune(catalog, prunePolicy, dryRun, force)
+		if err != nil {
+			fmt.Printf("❌ Erro na limpeza: %v\n", err)
+			return err
+		}
+
+		if len(actions) > 0 {
+			fmt.Printf("🗂️  %d arquivos ausentes no catálogo (política: %s):\n", len(actions), prunePolicy)
+			for _, action := range actions {
+				printPruneAction("   ", action)
+				for _, sidecar := range action.Sidecars {
+					printPruneAction("     + ", sidecar)
+				}
+			}
+			fmt.Println()
+		}
+	}
+
+	if dryRun {
+		fmt.Println("✅ Simulação concluída!")
+		return nil
+	}
+
+	if saved := c.libraryService.SaveFolderCovers(catalog.Clipes); saved > 0 {
+		fmt.Printf("🖼️  %d imagens de pasta salvas\n", saved)
+	}
+	if saved, err := c.libraryService.SaveLyrics(catalog.Clipes); err != nil {
+		fmt.Printf("⚠️  Erro ao salvar letras: %v\n", err)
+	} else if saved > 0 {
+		fmt.Printf("📝 %d letras salvas\n", saved)
+	}
+	c.generatePlaylists(runStart)
+
 	fmt.Println("✅ Download concluído com sucesso!")
 	return nil
 }
 
-func (c *CLI) downloadSpecific(titulo string) error {
+func printPruneAction(prefix string, action application.PruneAction) {
+	if action.Target != "" {
+		fmt.Printf("%s%s → %s\n", prefix, action.Path, action.Target)
+	} else {
+		fmt.Printf("%s%s\n", prefix, action.Path)
+	}
+}
+
+// downloadPublication runs the download pipeline for another publication.
+// Only the music videos have a listing page, so the catalog comes from the API.
+func (c *CLI) downloadPublication(value string, idiomas []string, prune, force, dryRun bool) error {
+	code, err := domain.ParsePublicationCode(value)
+	if err != nil {
+		fmt.Printf("❌ Erro: %v\n", err)
+		return err
+	}
+
+	pub, exists := c.publications[code]
+	if !exists {
+		pub = config.PublicationConfig{Code: code, Folder: code, Formats: []string{"MP3"}}
+		if code == domain.DefaultPublication {
+			pub.Folder = ""
+		}
+	}
+
+	c.scraper.SetPublication(pub.Code, pub.Formats)
+	if code != domain.DefaultPublication {
+		c.scraper.SetMode(web.ScrapingModeAPI, false)
+	}
+	c.downloadService.SetPublication(pub.Code, pub.Folder)
+	c.publication = pub.Code
+
+	return c.downloadAll(idiomas, prune, force, dryRun)
+}
+
+func (c *CLI) downloadSpecific(idiomas []string, titulo string, first, all bool) error {
 	showSmallBanner()
 	fmt.Printf("🎵 Procurando clipe: %s\n", titulo)
 	fmt.Printf("📁 Diretório de saída: %s\n", c.config.Download.OutputDirectory)
 	fmt.Println()
 
-	err := c.downloadService.DownloadSpecificClipe(c.config.Scraping.BaseURL, titulo)
+	if c.offline {
+		fmt.Printf("❌ Erro: %v\n", errOfflineDownload)
+		return errOfflineDownload
+	}
+
+	matches, err := c.downloadService.SearchClipes(idiomas, titulo)
+	if err != nil {
+		fmt.Printf("❌ Erro: %v\n", err)
+		return err
+	}
+
+	clipes, err := chooseClipes(matches, first, all, len(idiomas) > 1)
 	if err != nil {
 		fmt.Printf("❌ Erro: %v\n", err)
 		return err
 	}
+	if len(clipes) == 0 {
+		fmt.Println("Nenhum clipe escolhido.")
+		return nil
+	}
+
+	runStart := time.Now()
+	if err := c.downloadService.DownloadSelectedClipes(clipes); err != nil {
+		fmt.Printf("❌ Erro: %v\n", err)
+		return err
+	}
+	c.generatePlaylists(runStart)
 
 	fmt.Println("✅ Download concluído!")
 	return nil
 }
 
-func (c *CLI) checkNewClipes() error {
+const maxTitleCandidates = 10
+
+// chooseClipes picks the clips to download from a title search. A single
+// exact match is taken as is; otherwise --first or --all decide, or the user
+// is asked when running in a terminal.
+func chooseClipes(matches []application.TitleMatch, first, all, showLanguage bool) ([]domain.ClipeMusical, error) {
+	var exact []domain.ClipeMusical
+	for _, match := range matches {
+		if match.Exact {
+			exact = append(exact, match.Clipe)
+		}
+	}
+	if len(exact) == 1 {
+		return exact, nil
+	}
+
+	var clipes []domain.ClipeMusical
+	for _, match := range matches {
+		clipes = append(clipes, match.Clipe)
+	}
+	switch {
+	case all:
+		return clipes, nil
+	case first || len(clipes) == 1:
+		return clipes[:1], nil
+	}
+
+	candidates := clipes
+	if len(candidates) > maxTitleCandidates {
+		candidates = candidates[:maxTitleCandidates]
+	}
+
+	fmt.Printf("🔎 %d clipes encontrados:\n\n", len(clipes))
+	for i, clipe := range candidates {
+		if showLanguage {
+			fmt.Printf("%2d. %s [%s]\n", i+1, clipe.Titulo, clipe.GetIdioma())
+		} else {
+			fmt.Printf("%2d. %s\n", i+1, clipe.Titulo)
+		}
+	}
+	fmt.Println()
+
+	if !isTerminal(os.Stdin) {
+		return nil, fmt.Errorf("vários clipes encontrados, use --first ou --all")
+	}
+
+	fmt.Print("Escolha os números (ex.: 1,3), t para todos ou Enter para cancelar: ")
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && answer == "" {
+		return nil, nil
+	}
+	return pickCandidates(candidates, answer)
+}
+
+func pickCandidates(candidates []domain.ClipeMusical, answer string) ([]domain.ClipeMusical, error) {
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	if answer == "t" || answer == "todos" {
+		return candidates, nil
+	}
+
+	var picked []domain.ClipeMusical
+	for _, field := range strings.FieldsFunc(answer, func(r rune) bool { return r == ',' || r == ' ' }) {
+		number, err := strconv.Atoi(field)
+		if err != nil || number < 1 || number > len(candidates) {
+			return nil, fmt.Errorf("opção inválida: %s", field)
+		}
+		picked = append(picked, candidates[number-1])
+	}
+	return picked, nil
+}
+
+// isTerminal reports whether f is an interactive terminal.
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+func (c *CLI) generatePlaylists(runStart time.Time) {
+	playlists, err := c.libraryService.GeneratePlaylists(runStart)
+	if err != nil {
+		fmt.Printf("⚠️  Erro ao gerar playlists: %v\n", err)
+		return
+	}
+	if len(playlists) > 0 {
+		fmt.Printf("📜 %d playlists atualizadas\n", len(playlists))
+	}
+}
+
+func (c *CLI) checkNewClipes(idiomas []string) error {
 	showSmallBanner()
 	fmt.Println("🔍 Verificando novos clipes disponíveis...")
+	if c.offline {
+		fmt.Println("📴 Modo offline: usando apenas o cache")
+	}
 	fmt.Println()
 
-	novosClipes, err := c.downloadService.CheckForNewClipes(c.config.Scraping.BaseURL)
+	novosClipes, err := c.downloadService.CheckForNewClipes(idiomas)
 	if err != nil {
 		fmt.Printf("❌ Erro: %v\n", err)
 		return err
@@ -207,6 +854,12 @@ func (c *CLI) checkNewClipes() error {
 		if clipe.Ano > 0 {
 			fmt.Printf("   Ano: %d\n", clipe.Ano)
 		}
+		if len(idiomas) > 1 {
+			fmt.Printf("   Idioma: %s\n", clipe.GetIdioma())
+		}
+		if clipe.Duracao > 0 {
+			fmt.Printf("   Duração: %s\n", domain.FormatDuration(clipe.Duracao))
+		}
 		fmt.Printf("   URL: %s\n", clipe.URL)
 		fmt.Println()
 	}
@@ -215,6 +868,224 @@ func (c *CLI) checkNewClipes() error {
 	return nil
 }
 
+func (c *CLI) libraryDedupe(policyName string) error {
+	showSmallBanner()
+
+	policy, err := application.ParseDedupePolicy(policyName)
+	if err != nil {
+		fmt.Printf("❌ Erro: %v\n", err)
+		return err
+	}
+
+	fmt.Printf("🔍 Procurando duplicados (política: %s)...\n", policy)
+	fmt.Println()
+
+	groups, err := c.libraryService.Dedupe(policy)
+	if err != nil {
+		fmt.Printf("❌ Erro: %v\n", err)
+		return err
+	}
+
+	if len(groups) == 0 {
+		fmt.Println("✅ Nenhum arquivo duplicado encontrado!")
+		return nil
+	}
+
+	fmt.Printf("📋 Encontrados %d grupos de arquivos idênticos:\n\n", len(groups))
+	for i, group := range groups {
+		fmt.Printf("%d. %s (%d bytes)\n", i+1, group.Canonical, group.Size)
+		for _, duplicate := range group.Duplicates {
+			fmt.Printf("   ↳ %s\n", duplicate)
+		}
+		fmt.Println()
+	}
+
+	if policy == application.DedupePolicyReport {
+		fmt.Println("💡 Use --policy hardlink ou --policy remove para tratar as duplicatas.")
+	} else {
+		fmt.Println("✅ Duplicatas tratadas e registradas no manifesto!")
+	}
+	return nil
+}
+
+func (c *CLI) libraryVerify() (*application.VerifyReport, error) {
+	showSmallBanner()
+	fmt.Println("🔍 Verificando integridade da biblioteca...")
+	fmt.Println()
+
+	report, err := c.libraryService.Verify()
+	if err != nil {
+		fmt.Printf("❌ Erro: %v\n", err)
+		return nil, err
+	}
+
+	if len(report.Issues) == 0 {
+		fmt.Printf("✅ %d arquivos verificados, nenhum problema encontrado!\n", report.Checked)
+		return report, nil
+	}
+
+	fmt.Printf("📋 %d arquivos verificados, %d problemas encontrados:\n\n", report.Checked, len(report.Issues))
+	for i, issue := range report.Issues {
+		fmt.Printf("%d. [%s] %s\n", i+1, issue.Kind, issue.Path)
+		fmt.Printf("   %s\n", issue.Detail)
+		fmt.Println()
+	}
+
+	if repairable := len(report.Repairable()); repairable > 0 {
+		fmt.Printf("💡 Execute 'downloader-music library repair' para baixar novamente %d arquivos.\n", repairable)
+	}
+	return report, nil
+}
+
+func (c *CLI) libraryRepair() error {
+	report, err := c.libraryVerify()
+	if err != nil {
+		return err
+	}
+
+	fmt.Println()
+	fmt.Println("🔧 Reparando arquivos...")
+
+	repaired, err := c.libraryService.Repair(report)
+	if err != nil {
+		fmt.Printf("❌ Erro: %v\n", err)
+		return err
+	}
+
+	if repaired == 0 {
+		fmt.Println("✅ Nada para reparar!")
+		return nil
+	}
+
+	fmt.Printf("✅ %d arquivos baixados novamente!\n", repaired)
+	return nil
+}
+
+func (c *CLI) libraryExport(cmd *cobra.Command) error {
+	showSmallBanner()
+
+	formatName, _ := cmd.Flags().GetString("format")
+	format, err := application.ParseArchiveFormat(formatName)
+	if err != nil {
+		fmt.Printf("❌ Erro: %v\n", err)
+		return err
+	}
+
+	filter := application.ExportFilter{}
+	filter.YearFrom, _ = cmd.Flags().GetInt("year-from")
+	filter.YearTo, _ = cmd.Flags().GetInt("year-to")
+	filter.Categorias, _ = cmd.Flags().GetStringSlice("category")
+	if value, _ := cmd.Flags().GetString("lang"); value != "" {
+		filter.Idiomas, err = domain.ParseLanguages([]string{value})
+		if err != nil {
+			fmt.Printf("❌ Erro: %v\n", err)
+			return err
+		}
+	}
+
+	for flag, target := range map[string]*time.Time{"since": &filter.Since, "until": &filter.Until} {
+		value, _ := cmd.Flags().GetString(flag)
+		if value == "" {
+			continue
+		}
+		parsed, err := time.ParseInLocation("2006-01-02", value, time.Local)
+		if err != nil {
+			err = fmt.Errorf("data inválida em --%s: %s", flag, value)
+			fmt.Printf("❌ Erro: %v\n", err)
+			return err
+		}
+		if flag == "until" {
+			parsed = parsed.Add(24*time.Hour - time.Nanosecond)
+		}
+		*target = parsed
+	}
+
+	output, _ := cmd.Flags().GetString("output")
+	if output == "" {
+		output = fmt.Sprintf("clipes-%s.%s", time.Now().Format("2006-01-02"), format)
+	}
+
+	fmt.Printf("📦 Exportando biblioteca para %s...\n", output)
+	fmt.Println()
+
+	file, err := os.Create(output)
+	if err != nil {
+		err = fmt.Errorf("erro ao criar arquivo: %w", err)
+		fmt.Printf("❌ Erro: %v\n", err)
+		return err
+	}
+	defer file.Close()
+
+	total, err := c.libraryService.Export(file, format, filter)
+	if err != nil {
+		file.Close()
+		os.Remove(output)
+		fmt.Printf("❌ Erro: %v\n", err)
+		return err
+	}
+
+	fmt.Printf("✅ %d clipes exportados para %s\n", total, output)
+	return nil
+}
+
+func (c *CLI) libraryImport(archivePath string) error {
+	showSmallBanner()
+
+	format, err := application.DetectArchiveFormat(archivePath)
+	if err != nil {
+		fmt.Printf("❌ Erro: %v\n", err)
+		return err
+	}
+
+	file, err := os.Open(archivePath)
+	if err != nil {
+		err = fmt.Errorf("erro ao abrir arquivo: %w", err)
+		fmt.Printf("❌ Erro: %v\n", err)
+		return err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		fmt.Printf("❌ Erro: %v\n", err)
+		return err
+	}
+
+	fmt.Printf("📥 Importando %s...\n", archivePath)
+	fmt.Println()
+
+	result, err := c.libraryService.Import(file, info.Size(), format)
+	if err != nil {
+		fmt.Printf("❌ Erro: %v\n", err)
+		return err
+	}
+
+	fmt.Printf("✅ %d clipes importados, %d já existentes\n", len(result.Imported), len(result.Skipped))
+	if len(result.Failed) > 0 {
+		fmt.Printf("⚠️  %d arquivos com falha:\n", len(result.Failed))
+		for name, reason := range result.Failed {
+			fmt.Printf("   %s: %s\n", name, reason)
+		}
+		return fmt.Errorf("%d arquivos não puderam ser importados", len(result.Failed))
+	}
+	return nil
+}
+
+func (c *CLI) libraryRetag() error {
+	showSmallBanner()
+	fmt.Println("🏷️  Reaplicando tags ID3 na biblioteca...")
+	fmt.Println()
+
+	total, err := c.libraryService.Retag(c.tagger)
+	if err != nil {
+		fmt.Printf("❌ Erro: %v\n", err)
+		return err
+	}
+
+	fmt.Printf("✅ Tags atualizadas em %d arquivos!\n", total)
+	return nil
+}
+
 func (c *CLI) setOutputDirectory(dir string) error {
 	if dir[0] == '~' {
 		homeDir, err := os.UserHomeDir()